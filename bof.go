@@ -1,8 +1,11 @@
 package xls
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/binary"
 	"io"
+	"sync"
 	"unicode/utf16"
 )
 
@@ -12,8 +15,12 @@ type bof struct {
 	Size uint16
 }
 
-// read the utf16 string from reader.
-func (b *bof) utf16String(buf io.ReadSeeker, count uint32) string {
+// read the utf16 string from reader. count includes the terminating null character.
+func (b *bof) utf16String(buf *bytes.Reader, count uint32) string {
+	if count == 0 || int64(count)*2 > int64(buf.Len()) {
+		return ""
+	}
+
 	bts := make([]uint16, count)
 
 	err := binary.Read(buf, binary.LittleEndian, &bts)
@@ -26,11 +33,70 @@ func (b *bof) utf16String(buf io.ReadSeeker, count uint32) string {
 	return string(runes)
 }
 
-type biffHeader struct {
-	Ver    uint16
-	Type   uint16
-	IDMake uint16
-	Year   uint16
-	Flags  uint32
-	MinVer uint32
+// maxRecordSize is the largest record payload BIFF8 writers produce; longer data continues in CONTINUE records.
+const maxRecordSize = 8224
+
+// recordBufferSize is the read-ahead of a recordReader; it holds several maximum-size records.
+const recordBufferSize = 64 << 10
+
+// recordReaderPool recycles record readers together with their read-ahead and payload buffers.
+var recordReaderPool = sync.Pool{
+	New: func() interface{} {
+		return &recordReader{
+			br:   bufio.NewReaderSize(nil, recordBufferSize),
+			data: make([]byte, 0, 1<<13),
+		}
+	},
+}
+
+// recordReader reads BIFF records sequentially from a stream.
+// The payload returned by next is only valid until the following call.
+type recordReader struct {
+	br     *bufio.Reader
+	header [4]byte
+	data   []byte
+	pos    int64 // stream offset of the next record
+}
+
+// acquireRecordReader returns a pooled reader positioned at the given stream offset.
+func acquireRecordReader(rs io.ReadSeeker, pos int64) (*recordReader, error) {
+	if _, err := rs.Seek(pos, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	r, _ := recordReaderPool.Get().(*recordReader)
+	r.br.Reset(rs)
+	r.pos = pos
+
+	return r, nil
+}
+
+// release returns the reader to the pool; it must not be used afterwards.
+func (r *recordReader) release() {
+	r.br.Reset(nil)
+	recordReaderPool.Put(r)
+}
+
+// next reads the next record. It returns the record header, the payload and the
+// stream offset of the payload.
+func (r *recordReader) next() (bof, []byte, int64, error) {
+	if _, err := io.ReadFull(r.br, r.header[:]); err != nil {
+		return bof{}, nil, 0, err
+	}
+
+	b := bof{ID: binary.LittleEndian.Uint16(r.header[:]), Size: binary.LittleEndian.Uint16(r.header[2:])}
+
+	if cap(r.data) < int(b.Size) {
+		r.data = make([]byte, b.Size)
+	}
+
+	r.data = r.data[:b.Size]
+	if _, err := io.ReadFull(r.br, r.data); err != nil {
+		return b, nil, 0, err
+	}
+
+	pos := r.pos + 4
+	r.pos = pos + int64(b.Size)
+
+	return b, r.data, pos, nil
 }
@@ -47,15 +47,9 @@ type HyperLink struct {
 }
 
 // get the hyperlink string, use the public variable Url to get the original Url.
-func (h *HyperLink) String(_ *WorkBook) []string {
+func (h *HyperLink) String(wb *WorkBook) []string {
 	res := make([]string, h.LastColB-h.FristColB+1)
-	var str string
-
-	if h.IsURL {
-		str = fmt.Sprintf("%s(%s)", h.Description, h.URL)
-	} else {
-		str = h.ExtendedFilePath
-	}
+	str := h.cellString(wb, 0)
 
 	for i := range res {
 		res[i] = str
@@ -63,3 +57,11 @@ func (h *HyperLink) String(_ *WorkBook) []string {
 
 	return res
 }
+
+func (h *HyperLink) cellString(_ *WorkBook, _ int) string {
+	if h.IsURL {
+		return fmt.Sprintf("%s(%s)", h.Description, h.URL)
+	}
+
+	return h.ExtendedFilePath
+}
@@ -0,0 +1,314 @@
+//nolint:mnd
+package xls
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+)
+
+// Chart is a chart sheet or a chart embedded in a worksheet, decoded from its chart substream.
+type Chart struct {
+	// Type is the type of the chart's first chart group.
+	Type ChartType
+	// Stacked is set for stacked bar, column, line and area charts, and PercentStacked when
+	// they are stacked to 100%.
+	Stacked        bool
+	PercentStacked bool
+	// Title is the text of the chart title, or empty if it has none or it is generated.
+	Title  string
+	Series []ChartSeries
+}
+
+// ChartSeries is a data series of a chart, with the ranges it takes its data from and the
+// values Excel cached when the file was saved.
+type ChartSeries struct {
+	Name    string
+	NameRef []ChartRef
+	// CategoryRef is the source of the categories, or empty if they are literals or numbered 1, 2, ...
+	CategoryRef []ChartRef
+	Categories  []string
+	// ValueRef is the source of the values, or empty if they are literals.
+	ValueRef []ChartRef
+	// Values holds the cached values, NaN for empty cells and errors.
+	Values []float64
+}
+
+// ChartRef is a range a chart takes data from. Sheet has the file name in brackets
+// for ranges in other workbooks, and is empty if the sheet is unknown.
+type ChartRef struct {
+	Sheet string
+	CellRange
+}
+
+// ChartType is the type of a chart group.
+type ChartType int
+
+const (
+	ChartColumn ChartType = iota + 1
+	ChartBar
+	ChartLine
+	ChartPie
+	ChartDoughnut
+	// ChartOfPie is a pie of pie or bar of pie chart.
+	ChartOfPie
+	ChartArea
+	ChartScatter
+	ChartBubble
+	ChartRadar
+	ChartFilledRadar
+	ChartSurface
+)
+
+func (t ChartType) String() string {
+	switch t {
+	case ChartColumn:
+		return "column"
+	case ChartBar:
+		return "bar"
+	case ChartLine:
+		return "line"
+	case ChartPie:
+		return "pie"
+	case ChartDoughnut:
+		return "doughnut"
+	case ChartOfPie:
+		return "of-pie"
+	case ChartArea:
+		return "area"
+	case ChartScatter:
+		return "scatter"
+	case ChartBubble:
+		return "bubble"
+	case ChartRadar:
+		return "radar"
+	case ChartFilledRadar:
+		return "filled-radar"
+	case ChartSurface:
+		return "surface"
+	}
+
+	return "unknown"
+}
+
+// Charts returns the charts of the sheet: the chart of a chart sheet, or the charts embedded in a worksheet.
+func (w *WorkSheet) Charts() []Chart {
+	return w.charts
+}
+
+// chartParser decodes the records of a chart substream into a Chart.
+type chartParser struct {
+	wb    *WorkBook
+	chart Chart
+	typed bool // the first chart group's type has been read
+
+	last        uint16 // the previous record, which a BEGIN record opens a block for
+	depth       int    // BEGIN/END nesting
+	seriesDepth int    // depth of the current SERIES block, 0 outside
+	textDepth   int    // depth of the current TEXT block, 0 outside
+	text        string // SERIESTEXT of the current TEXT block
+	textLink    uint16 // OBJECTLINK of the current TEXT block: 1 for the chart title
+	brai        byte   // the kind of the last BRAI record of the series block
+	cache       uint16 // the SIINDEX of the cached values that follow: 1 values, 2 categories
+}
+
+// parse decodes one record of the chart substream.
+func (p *chartParser) parse(id uint16, data []byte) {
+	defer func() { p.last = id }()
+
+	switch id {
+	case 0x1033: // BEGIN
+		p.depth++
+
+		switch p.last {
+		case 0x1025: // TEXT
+			p.textDepth, p.text, p.textLink = p.depth, "", 0
+		case 0x1003: // SERIES
+			p.seriesDepth = p.depth
+		}
+	case 0x1034: // END
+		if p.depth == p.textDepth {
+			if p.textLink == 1 {
+				p.chart.Title = p.text
+			}
+
+			p.textDepth = 0
+		}
+
+		if p.depth == p.seriesDepth {
+			p.seriesDepth = 0
+		}
+
+		p.depth--
+	case 0x1003: // SERIES
+		p.chart.Series = append(p.chart.Series, ChartSeries{})
+	case 0x1051: // BRAI
+		p.setBRAI(data)
+	case 0x100D: // SERIESTEXT: reserved, then a string with an 8-bit length
+		if len(data) < 4 {
+			break
+		}
+
+		p.wb.strReader.Reset(data[3:])
+		text, _ := p.wb.getString(&p.wb.strReader, uint16(data[2]))
+
+		switch {
+		case p.textDepth > 0:
+			p.text = text
+		case p.seriesDepth > 0 && p.brai == 0:
+			p.chart.Series[len(p.chart.Series)-1].Name = text
+		}
+	case 0x1027: // OBJECTLINK
+		if p.textDepth > 0 && len(data) >= 2 {
+			p.textLink = binary.LittleEndian.Uint16(data)
+		}
+	case 0x1017, 0x1018, 0x1019, 0x101A, 0x101B, 0x103E, 0x103F, 0x1040, 0x1035:
+		p.setType(id, data)
+	case 0x1065: // SIINDEX
+		if len(data) >= 2 {
+			p.cache = binary.LittleEndian.Uint16(data)
+		}
+	case 0x203, 0x204, 0x201, 0x205: // NUMBER, LABEL, BLANK, BOOLERR
+		p.addCached(id, data)
+	}
+}
+
+// setBRAI decodes a BRAI record of a series: what it links (0 the name, 1 the values,
+// 2 the categories), how (1 literal, 2 reference), flags, a number format and a formula.
+func (p *chartParser) setBRAI(data []byte) {
+	if p.seriesDepth == 0 || p.textDepth > 0 || len(data) < 8 {
+		return
+	}
+
+	p.brai = data[0]
+	if data[1] != 2 {
+		return
+	}
+
+	rgce := data[8:]
+	rgce = rgce[:min(int(binary.LittleEndian.Uint16(data[6:])), len(rgce))]
+
+	var refs []ChartRef
+	for _, area := range formulaAreas3d(rgce) {
+		refs = append(refs, ChartRef{Sheet: p.wb.externSheetName(area.ixti), CellRange: area.CellRange})
+	}
+
+	s := &p.chart.Series[len(p.chart.Series)-1]
+
+	switch p.brai {
+	case 0:
+		s.NameRef = refs
+	case 1:
+		s.ValueRef = refs
+	case 2:
+		s.CategoryRef = refs
+	}
+}
+
+// setType decodes the record giving the type of a chart group. Only the first group counts.
+func (p *chartParser) setType(id uint16, data []byte) {
+	if p.typed {
+		return
+	}
+
+	p.typed = true
+
+	field := func(off int) uint16 {
+		if len(data) < off+2 {
+			return 0
+		}
+
+		return binary.LittleEndian.Uint16(data[off:])
+	}
+
+	c := &p.chart
+
+	switch id {
+	case 0x1017: // BAR: overlap, gap, then horizontal, stacked and 100% flags
+		flags := field(4)
+
+		c.Type = ChartColumn
+		if flags&0x1 != 0 {
+			c.Type = ChartBar
+		}
+
+		c.Stacked, c.PercentStacked = flags&0x2 != 0, flags&0x4 != 0
+	case 0x1018, 0x101A: // LINE, AREA: stacked and 100% flags
+		flags := field(0)
+
+		c.Type = ChartLine
+		if id == 0x101A {
+			c.Type = ChartArea
+		}
+
+		c.Stacked, c.PercentStacked = flags&0x1 != 0, flags&0x2 != 0
+	case 0x1019: // PIE: first slice angle, doughnut hole size
+		c.Type = ChartPie
+		if field(2) != 0 {
+			c.Type = ChartDoughnut
+		}
+	case 0x101B: // SCATTER: bubble size ratio and meaning, then a bubble chart flag
+		c.Type = ChartScatter
+		if field(4)&0x1 != 0 {
+			c.Type = ChartBubble
+		}
+	case 0x103E:
+		c.Type = ChartRadar
+	case 0x1040:
+		c.Type = ChartFilledRadar
+	case 0x103F:
+		c.Type = ChartSurface
+	case 0x1035:
+		c.Type = ChartOfPie
+	}
+}
+
+// addCached stores a cached value of a series: the row is the point, the column the series.
+func (p *chartParser) addCached(id uint16, data []byte) {
+	if len(data) < 6 || (p.cache != 1 && p.cache != 2) {
+		return
+	}
+
+	point, series := int(binary.LittleEndian.Uint16(data)), int(binary.LittleEndian.Uint16(data[2:]))
+	if series >= len(p.chart.Series) || point > maxRowIndex {
+		return
+	}
+
+	value, text := math.NaN(), ""
+
+	switch id {
+	case 0x203: // NUMBER
+		if len(data) >= 14 {
+			value = math.Float64frombits(binary.LittleEndian.Uint64(data[6:]))
+			text = strconv.FormatFloat(value, 'f', -1, 64)
+		}
+	case 0x204: // LABEL
+		if len(data) >= 9 {
+			p.wb.strReader.Reset(data[8:])
+			text, _ = p.wb.getString(&p.wb.strReader, binary.LittleEndian.Uint16(data[6:]))
+		}
+	case 0x205: // BOOLERR: a boolean unless the error flag is set
+		if len(data) >= 8 && data[7] == 0 {
+			value, text = float64(data[6]), "FALSE"
+			if data[6] != 0 {
+				text = "TRUE"
+			}
+		}
+	}
+
+	s := &p.chart.Series[series]
+
+	if p.cache == 1 {
+		for len(s.Values) <= point {
+			s.Values = append(s.Values, math.NaN())
+		}
+
+		s.Values[point] = value
+	} else {
+		for len(s.Categories) <= point {
+			s.Categories = append(s.Categories, "")
+		}
+
+		s.Categories[point] = text
+	}
+}
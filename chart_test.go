@@ -0,0 +1,149 @@
+package xls
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// biffRecord encodes a BIFF record.
+func biffRecord(id uint16, data ...[]byte) []byte {
+	body := bytes.Join(data, nil)
+
+	res := binary.LittleEndian.AppendUint16(nil, id)
+	res = binary.LittleEndian.AppendUint16(res, uint16(len(body)))
+
+	return append(res, body...)
+}
+
+// u16 encodes little-endian 16-bit values.
+func u16(values ...uint16) []byte {
+	var res []byte
+	for _, v := range values {
+		res = binary.LittleEndian.AppendUint16(res, v)
+	}
+
+	return res
+}
+
+// TestEmbeddedChart parses a worksheet with a column chart embedded between its cells:
+// the chart gets its title, series and cached values, and its records stay out of the worksheet.
+func TestEmbeddedChart(t *testing.T) {
+	t.Parallel()
+
+	number := func(row, col uint16, v float64) []byte {
+		return biffRecord(0x203, u16(row, col, 15), binary.LittleEndian.AppendUint64(nil, math.Float64bits(v)))
+	}
+
+	label := func(row, col uint16, s string) []byte {
+		return biffRecord(0x204, u16(row, col, 15, uint16(len(s))), []byte{0}, []byte(s))
+	}
+
+	seriesText := func(s string) []byte {
+		return biffRecord(0x100D, u16(0), []byte{byte(len(s)), 0}, []byte(s))
+	}
+
+	// BRAI with a ptgArea3d on the first EXTERNSHEET entry
+	brai := func(id byte, rwFirst, rwLast, col uint16) []byte {
+		return biffRecord(0x1051, []byte{id, 2}, u16(0, 0, 11), []byte{0x3B}, u16(0, rwFirst, rwLast, col, col))
+	}
+
+	begin, end := biffRecord(0x1033), biffRecord(0x1034)
+
+	chart := bytes.Join([][]byte{
+		biffRecord(0x809, u16(0x600, 0x20), make([]byte, 12)),
+		biffRecord(0x1002, make([]byte, 16)),
+		begin,
+		biffRecord(0x1003, u16(3, 1, 2, 2, 1, 0)),
+		begin,
+		biffRecord(0x1051, []byte{0, 1}, u16(0, 0, 0)),
+		seriesText("Revenue"),
+		brai(1, 1, 2, 1),
+		brai(2, 1, 2, 0),
+		end,
+		biffRecord(0x1025, make([]byte, 32)),
+		begin,
+		seriesText("Sales by region"),
+		biffRecord(0x1027, u16(1, 0, 0)),
+		end,
+		biffRecord(0x1014, make([]byte, 20)),
+		begin,
+		biffRecord(0x1017, u16(0, 150, 0x2)),
+		end,
+		end,
+		biffRecord(0x1065, u16(2)),
+		label(0, 0, "North"),
+		label(1, 0, "South"),
+		biffRecord(0x1065, u16(1)),
+		number(0, 0, 120),
+		number(1, 0, 80.5),
+		biffRecord(0x0A),
+	}, nil)
+
+	stream := bytes.Join([][]byte{
+		biffRecord(0x809, u16(0x600, 0x10), make([]byte, 12)),
+		number(0, 0, 1),
+		chart,
+		number(1, 0, 2),
+		biffRecord(0x0A),
+	}, nil)
+
+	wb := &WorkBook{rs: bytes.NewReader(stream)}
+	sheet := &WorkSheet{wb: wb, bs: &boundsheet{}, Name: "Data"}
+	wb.sheets = []*WorkSheet{sheet}
+
+	handleSupBook(wb, u16(1, 0x0401))
+	handleExternSheet(wb, u16(1, 0, 0, 0))
+
+	if err := sheet.parse(context.Background()); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	if sheet.Row(0).Col(0) != "1" || sheet.Row(1) == nil || sheet.Row(1).Col(0) != "2" {
+		t.Errorf("worksheet cells were not kept apart from the chart")
+	}
+
+	want := []Chart{{
+		Type:    ChartColumn,
+		Stacked: true,
+		Title:   "Sales by region",
+		Series: []ChartSeries{{
+			Name:        "Revenue",
+			CategoryRef: []ChartRef{{Sheet: "Data", CellRange: CellRange{FirstRowB: 1, LastRowB: 2}}},
+			Categories:  []string{"North", "South"},
+			ValueRef:    []ChartRef{{Sheet: "Data", CellRange: CellRange{FirstRowB: 1, LastRowB: 2, FristColB: 1, LastColB: 1}}},
+			Values:      []float64{120, 80.5},
+		}},
+	}}
+
+	if got := sheet.Charts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Charts() = %+v, want %+v", got, want)
+	}
+}
+
+func TestChartTypes(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		id   uint16
+		data []byte
+		want ChartType
+	}{
+		{0x1017, []byte{0, 0, 150, 0, 1, 0}, ChartBar},
+		{0x1018, []byte{0, 0}, ChartLine},
+		{0x1019, []byte{0, 0, 50, 0, 0, 0}, ChartDoughnut},
+		{0x1019, []byte{0, 0, 0, 0, 0, 0}, ChartPie},
+		{0x101B, []byte{100, 0, 1, 0, 1, 0}, ChartBubble},
+		{0x1040, []byte{0, 0, 0, 0}, ChartFilledRadar},
+	} {
+		p := &chartParser{wb: &WorkBook{}}
+		p.parse(tt.id, tt.data)
+
+		if p.chart.Type != tt.want {
+			t.Errorf("record %#x: type %s, want %s", tt.id, p.chart.Type, tt.want)
+		}
+	}
+}
@@ -11,6 +11,9 @@ import (
 // content type
 type contentHandler interface {
 	String(*WorkBook) []string
+	// cellString renders the column at the given offset from FirstCol
+	// without building the strings of the other columns.
+	cellString(wb *WorkBook, offset int) string
 	FirstCol() uint16
 	LastCol() uint16
 }
@@ -57,68 +60,64 @@ type XfRk struct {
 // String converts the RK value to its formatted string representation,
 // depending on the associated cell format (Xf) and number format definition.
 func (xf *XfRk) String(workBook *WorkBook) string {
-	idx := int(xf.Index)
-	if idx >= len(workBook.Xfs) {
-		return xf.Rk.String() // fallback: no format info
+	return workBook.formatNumber(xf.Index, xf.Rk.value(), xf.Rk.String())
+}
+
+// formatNumber renders the value of a number cell with the number format of its XF record.
+// plain is the value as shown when the format is not a date.
+func (wb *WorkBook) formatNumber(xfIndex uint16, value float64, plain string) string {
+	idx := int(xfIndex)
+	if idx >= len(wb.Xfs) {
+		return plain // fallback: no format info
 	}
 
-	formatNo := workBook.Xfs[idx].formatNo()
+	formatNo := wb.Xfs[idx].formatNo()
+
+	if str, ok := wb.renderBuiltin(formatNo, value); ok {
+		return str
+	}
 
 	// If format number is user-defined
 	if formatNo >= 164 {
-		return xf.renderCustomFormat(workBook, formatNo)
+		return wb.renderCustomFormat(formatNo, value, plain)
 	}
 
 	// Built-in date/time formats (based on OpenOffice Excel format spec)
 	if isBuiltinDateFormat(formatNo) {
-		return xf.renderDate(workBook)
+		return wb.renderDate(builtinDateKind(formatNo), value, plain)
 	}
 
-	return xf.Rk.String() // fallback: plain number
+	return plain // fallback: plain number
 }
 
 // renderCustomFormat handles user-defined Excel formats (formatNo >= 164).
-func (xf *XfRk) renderCustomFormat(workBook *WorkBook, formatNo uint16) string {
-	formatter := workBook.Formats[formatNo]
+func (wb *WorkBook) renderCustomFormat(formatNo uint16, value float64, plain string) string {
+	formatter := wb.Formats[formatNo]
 	if formatter == nil {
-		return xf.Rk.String()
+		return plain
 	}
 
-	formatStr := strings.ToLower(formatter.str)
+	formatStr := strings.ToLower(cyrillicDateTokens.Replace(formatter.str))
 
-	// Treat as numeric if it looks like a general or number format
-	if isNumericFormat(formatStr) {
-		return xf.Rk.String()
+	// Only formats with date or time tokens are dates; "General" has a G but is not one
+	if formatStr == "general" || !isDatePattern(formatStr) {
+		return plain
 	}
 
-	// Otherwise treat as a date
-	return xf.renderDate(workBook)
+	return wb.renderDate(customDateKind(formatStr), value, plain)
 }
 
-// renderDate extracts the underlying float value and renders it as a date.
-func (xf *XfRk) renderDate(workBook *WorkBook) string {
-	intVal, floatVal, isFloat := xf.Rk.number()
-	if !isFloat {
-		floatVal = float64(intVal)
-	}
-
-	t := timeFromExcelTime(floatVal, workBook.dateMode == 1)
+// cyrillicDateTokens maps the day, month and year tokens of Russian date formats such as
+// "ДД.ММ.ГГГГ", which some files store, to their Latin counterparts.
+var cyrillicDateTokens = strings.NewReplacer("Д", "d", "д", "d", "М", "m", "м", "m", "Г", "y", "г", "y")
 
-	// Use a general format for user-defined dates
-	return t.Format("02.01.2006")
-}
+// renderDate renders the serial value with the layout configured for its kind.
+func (wb *WorkBook) renderDate(kind dateKind, value float64, plain string) string {
+	if wb.opts.RawSerials {
+		return plain
+	}
 
-// isNumericFormat returns true if the format string appears to represent a number.
-func isNumericFormat(format string) bool {
-	return (format == "general" ||
-		strings.Contains(format, "#") ||
-		strings.Contains(format, ".00")) &&
-		!strings.Contains(format, "m/y") &&
-		!strings.Contains(format, "d/y") &&
-		!strings.Contains(format, "m.y") &&
-		!strings.Contains(format, "d.y") &&
-		!strings.Contains(format, "h:") &&
-		!strings.Contains(format, "д.г")
+	return wb.ConvertSerial(value).Format(wb.opts.layout(kind))
 }
 
 // isBuiltinDateFormat checks if a format number is one of Excel's standard date formats.
@@ -127,8 +126,87 @@ func isBuiltinDateFormat(fNo uint16) bool {
 		(27 <= fNo && fNo <= 36) || (50 <= fNo && fNo <= 58)
 }
 
+// dateKind tells which parts of a timestamp a date format displays.
+type dateKind int
+
+const (
+	dateKindDate dateKind = iota
+	dateKindTime
+	dateKindDateTime
+)
+
+// builtinDateKind classifies the built-in formats accepted by isBuiltinDateFormat.
+func builtinDateKind(fNo uint16) dateKind {
+	switch {
+	case fNo == 22:
+		return dateKindDateTime
+	case (32 <= fNo && fNo <= 35) || (55 <= fNo && fNo <= 58):
+		return dateKindTime
+	}
+
+	return dateKindDate
+}
+
+// customDateKind classifies a lower-cased user-defined date format by the tokens it uses.
+// Quoted literals, escaped characters and bracketed sections such as colors are ignored.
+func customDateKind(format string) dateKind {
+	var hasDate, hasTime, hasMonth bool
+
+	for i := 0; i < len(format); i++ {
+		switch c := format[i]; c {
+		case '"':
+			if end := strings.IndexByte(format[i+1:], '"'); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(format)
+			}
+		case '[':
+			if end := strings.IndexByte(format[i+1:], ']'); end >= 0 {
+				// [h], [mm] and [ss] are elapsed time
+				if strings.Trim(format[i+1:i+1+end], "hms") == "" {
+					hasTime = true
+				}
+
+				i += end + 1
+			}
+		case '\\':
+			i++
+		case 'd', 'y':
+			hasDate = true
+		case 'h', 's':
+			hasTime = true
+		case 'm':
+			hasMonth = true
+		}
+	}
+
+	// A lone "m" is a month; next to hours or seconds it is minutes.
+	if hasMonth && !hasTime {
+		hasDate = true
+	}
+
+	switch {
+	case hasDate && hasTime:
+		return dateKindDateTime
+	case hasTime:
+		return dateKindTime
+	}
+
+	return dateKindDate
+}
+
 type RK uint32
 
+// value returns the number encoded by the RK value.
+func (rk RK) value() float64 {
+	i, f, isFloat := rk.number()
+	if !isFloat {
+		return float64(i)
+	}
+
+	return f
+}
+
 // number decodes the RK-encoded value into either an integer or a float.
 // The RK format is a compact representation used in BIFF records.
 func (rk RK) number() (intNum int64, floatNum float64, isFloat bool) {
@@ -162,10 +240,11 @@ func (rk RK) number() (intNum int64, floatNum float64, isFloat bool) {
 }
 
 // String returns the RK value formatted as either a float or int string.
+// Floats are rendered like Excel's "General" format.
 func (rk RK) String() string {
 	i, f, isFloat := rk.number()
 	if isFloat {
-		return strconv.FormatFloat(f, 'f', -1, 64)
+		return formatGeneral(f)
 	}
 
 	return strconv.FormatInt(i, 10)
@@ -212,6 +291,10 @@ func (c *MulrkCol) String(wb *WorkBook) []string {
 	return res
 }
 
+func (c *MulrkCol) cellString(wb *WorkBook, offset int) string {
+	return c.Xfrks[offset].String(wb)
+}
+
 type MulBlankCol struct {
 	Col
 	Xfs      []uint16
@@ -233,17 +316,26 @@ func (c *MulBlankCol) String(_ *WorkBook) []string {
 	return make([]string, len(c.Xfs))
 }
 
+func (c *MulBlankCol) cellString(_ *WorkBook, _ int) string {
+	return ""
+}
+
 type NumberCol struct {
 	Col
 	Index uint16
 	Float float64
 }
 
-// String returns the floating-point value of the NumberCol as a string.
+// String returns the floating-point value of the NumberCol as a string, rendered like
+// Excel's "General" format, or as a date or time if the cell's number format is one.
 //
 // This corresponds to the BIFF `NUMBER` record, which stores an IEEE 754 float.
-func (c *NumberCol) String(_ *WorkBook) []string {
-	return []string{strconv.FormatFloat(c.Float, 'f', -1, 64)}
+func (c *NumberCol) String(wb *WorkBook) []string {
+	return []string{c.cellString(wb, 0)}
+}
+
+func (c *NumberCol) cellString(wb *WorkBook, _ int) string {
+	return wb.formatNumber(c.Index, c.Float, formatGeneral(c.Float))
 }
 
 // FormulaStringCol represents a formula whose result is a string literal.
@@ -258,6 +350,10 @@ func (c *FormulaStringCol) String(_ *WorkBook) []string {
 	return []string{c.RenderedValue}
 }
 
+func (c *FormulaStringCol) cellString(_ *WorkBook, _ int) string {
+	return c.RenderedValue
+}
+
 // FormulaCol represents a cell that contains a formula,
 // but whose result is not a string and must be interpreted from raw bytes.
 //
@@ -279,6 +375,10 @@ func (c *FormulaCol) String(_ *WorkBook) []string {
 	return []string{"FormulaCol"}
 }
 
+func (c *FormulaCol) cellString(_ *WorkBook, _ int) string {
+	return "FormulaCol"
+}
+
 // RkCol represents a single cell that stores a value in RK format (compact int/float).
 type RkCol struct {
 	Col
@@ -291,6 +391,10 @@ func (c *RkCol) String(wb *WorkBook) []string {
 	return []string{c.Xfrk.String(wb)}
 }
 
+func (c *RkCol) cellString(wb *WorkBook, _ int) string {
+	return c.Xfrk.String(wb)
+}
+
 // LabelsstCol represents a cell that refers to the shared string table (SST).
 type LabelsstCol struct {
 	Col
@@ -298,9 +402,14 @@ type LabelsstCol struct {
 	Sst uint32 // Index into the shared string table (wb.sst)
 }
 
-// String returns the resolved string from the SST at the given index.
+// String returns the resolved string from the SST at the given index,
+// or an empty string if the index lies outside the table.
 func (c *LabelsstCol) String(wb *WorkBook) []string {
-	return []string{wb.sst[int(c.Sst)]}
+	return []string{wb.sstString(c.Sst)}
+}
+
+func (c *LabelsstCol) cellString(wb *WorkBook, _ int) string {
+	return wb.sstString(c.Sst)
 }
 
 // labelCol represents a legacy LABEL record containing a plain string,
@@ -317,6 +426,10 @@ func (c *labelCol) String(_ *WorkBook) []string {
 	return []string{c.Str}
 }
 
+func (c *labelCol) cellString(_ *WorkBook, _ int) string {
+	return c.Str
+}
+
 // BlankCol represents a cell that is visually empty but may still have formatting applied.
 // This is common in sparse worksheets or cells with just borders/colors and no content.
 type BlankCol struct {
@@ -328,3 +441,7 @@ type BlankCol struct {
 func (c *BlankCol) String(_ *WorkBook) []string {
 	return []string{""}
 }
+
+func (c *BlankCol) cellString(_ *WorkBook, _ int) string {
+	return ""
+}
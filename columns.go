@@ -0,0 +1,107 @@
+//nolint:mnd
+package xls
+
+import (
+	"encoding/binary"
+	"math"
+	"slices"
+)
+
+// maxDigitWidth is the pixel width of the widest digit in the default font (Calibri 11 / Arial 10),
+// which Excel uses to convert character widths to pixels.
+const maxDigitWidth = 7
+
+// defaultColumnWidth is the DEFCOLWIDTH Excel writes when a sheet has none, in characters.
+const defaultColumnWidth = 8
+
+// ColumnInfo describes a range of columns sharing the same formatting, from a COLINFO record.
+type ColumnInfo struct {
+	// First and Last are the zero-based indexes of the first and last column in the range.
+	First, Last int
+	// Width is the column width in characters of the default font's widest digit, including padding.
+	Width float64
+	// Pixels is Width in screen pixels at 100% zoom.
+	Pixels int
+	// CustomWidth is set when the width was changed from the default.
+	CustomWidth bool
+	Hidden      bool
+	// OutlineLevel is the column's grouping depth, 0 to 7.
+	OutlineLevel int
+	// Collapsed is set when the group ending next to these columns is collapsed.
+	Collapsed bool
+	// XF is the index of the default cell format of the columns.
+	XF uint16
+}
+
+// Columns returns the column ranges with explicit formatting, in file order.
+// Columns not covered by any range have DefaultColumnWidth. The slice is a copy.
+func (w *WorkSheet) Columns() []ColumnInfo {
+	return slices.Clone(w.columns)
+}
+
+// Column returns the formatting of column i, falling back to the sheet defaults.
+func (w *WorkSheet) Column(i int) ColumnInfo {
+	for _, col := range w.columns {
+		if col.First <= i && i <= col.Last {
+			col.First, col.Last = i, i
+
+			return col
+		}
+	}
+
+	width := w.DefaultColumnWidth()
+
+	return ColumnInfo{First: i, Last: i, Width: width, Pixels: widthToPixels(width), XF: 15}
+}
+
+// DefaultColumnWidth returns the width, in characters, of columns without explicit formatting.
+// It is taken from STANDARDWIDTH if present, or else computed from DEFCOLWIDTH.
+func (w *WorkSheet) DefaultColumnWidth() float64 {
+	if w.standardWidth != 0 {
+		return float64(w.standardWidth) / 256
+	}
+
+	chars := defaultColumnWidth
+	if w.defColWidth != 0 {
+		chars = int(w.defColWidth)
+	}
+
+	// DEFCOLWIDTH excludes the 5 pixels of cell padding, and Excel rounds the default up to a multiple of 8 pixels
+	pixels := (chars*maxDigitWidth + 5 + 7) / 8 * 8
+
+	return math.Trunc(float64(pixels)/maxDigitWidth*256) / 256
+}
+
+// widthToPixels converts a width in characters to pixels.
+func widthToPixels(width float64) int {
+	return int((256*width + math.Trunc(128.0/maxDigitWidth)) / 256 * maxDigitWidth)
+}
+
+// addColInfo decodes a COLINFO record.
+func (w *WorkSheet) addColInfo(data []byte) {
+	if len(data) < 10 {
+		return
+	}
+
+	first := int(binary.LittleEndian.Uint16(data))
+	last := int(binary.LittleEndian.Uint16(data[2:]))
+
+	if last < first {
+		return
+	}
+
+	width := float64(binary.LittleEndian.Uint16(data[4:])) / 256
+	flags := binary.LittleEndian.Uint16(data[8:])
+
+	w.columns = append(w.columns, ColumnInfo{
+		First:        first,
+		Last:         last,
+		Width:        width,
+		Pixels:       widthToPixels(width),
+		CustomWidth:  flags&0x2 != 0,
+		Hidden:       flags&0x1 != 0,
+		OutlineLevel: int(flags >> 8 & 0x7),
+		Collapsed:    flags&0x1000 != 0,
+		XF:           binary.LittleEndian.Uint16(data[6:]),
+	})
+}
@@ -0,0 +1,70 @@
+package xls
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tealeg/xlsx"
+)
+
+// TestColumns checks the column widths against the same workbooks saved as xlsx.
+func TestColumns(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"bigtable", "issue47", "negatives", "superstore"} {
+		name := name
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			wb, err := Open("testdata/" + name + ".xls")
+			if err != nil {
+				t.Fatalf("failed to open XLS file: %v", err)
+			}
+
+			xlsxFile, err := xlsx.OpenFile("testdata/" + name + ".xlsx")
+			if err != nil {
+				t.Fatalf("failed to open XLSX file: %v", err)
+			}
+
+			sheet := wb.GetSheet(0)
+
+			columns := sheet.Columns()
+			if len(columns) == 0 {
+				t.Fatal("no columns")
+			}
+
+			columns[0].Width = -1
+			if sheet.Columns()[0].Width == -1 {
+				t.Error("Columns() returned the sheet's own slice")
+			}
+
+			for _, col := range xlsxFile.Sheets[0].Cols {
+				if col == nil {
+					continue
+				}
+
+				// xlsx columns are one-based
+				got := sheet.Column(col.Min - 1)
+				if math.Abs(got.Width-col.Width) > 0.05 || got.Hidden != col.Hidden {
+					t.Errorf("column %d: width %v hidden %v, want width %v hidden %v",
+						col.Min-1, got.Width, got.Hidden, col.Width, col.Hidden)
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultColumnWidth(t *testing.T) {
+	t.Parallel()
+
+	sheet := &WorkSheet{defColWidth: 8}
+	if got := sheet.Column(3); got.Width != 9.140625 || got.Pixels != 64 {
+		t.Errorf("default column: width %v, %d pixels; want 9.140625, 64 pixels", got.Width, got.Pixels)
+	}
+
+	sheet.standardWidth = 10 * 256
+	if got := sheet.DefaultColumnWidth(); got != 10 {
+		t.Errorf("DefaultColumnWidth() = %v, want 10", got)
+	}
+}
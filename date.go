@@ -122,3 +122,19 @@ func timeFromExcelTime(excelTime float64, date1904 bool) time.Time {
 
 	return baseDate.Add(days).Add(frac)
 }
+
+// Date1904 reports whether the workbook uses the 1904 date system, in which serial 0 is 1904-01-01.
+func (wb *WorkBook) Date1904() bool {
+	return wb.dateMode == 1
+}
+
+// ConvertSerial converts an Excel serial date number to a time, honoring the workbook's
+// date system. The wall clock is placed in Options.Location, or UTC when none is set.
+func (wb *WorkBook) ConvertSerial(serial float64) time.Time {
+	t := timeFromExcelTime(serial, wb.Date1904())
+	if wb.opts.Location == nil {
+		return t
+	}
+
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), wb.opts.Location)
+}
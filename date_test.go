@@ -0,0 +1,162 @@
+package xls
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCustomDateKind(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format string
+		want   dateKind
+	}{
+		{"dd/mm/yyyy", dateKindDate},
+		{"mmm-yy", dateKindDate},
+		{"hh:mm:ss", dateKindTime},
+		{"h:mm am/pm", dateKindTime},
+		{"[h]:mm", dateKindTime},
+		{"yyyy-mm-dd hh:mm", dateKindDateTime},
+		{`h:"12";@`, dateKindTime},
+		{"[$-409]d-mmm", dateKindDate},
+	}
+
+	for _, tt := range tests {
+		if got := customDateKind(tt.format); got != tt.want {
+			t.Errorf("customDateKind(%q) = %v, want %v", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestDateOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts *Options
+		want string
+	}{
+		{"default", nil, "30.12.1899"},
+		{"time layout", &Options{TimeLayout: "15:04:05"}, "18:00:00"},
+		{"date layout only", &Options{DateLayout: "2006-01-02"}, "1899-12-30"},
+		{"raw serials", &Options{RawSerials: true, TimeLayout: "15:04:05"}, "0.75"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			wb, err := OpenContext(context.Background(), "testdata/times.xls", tt.opts)
+			if err != nil {
+				t.Fatalf("failed to open XLS file: %v", err)
+			}
+
+			// B1 is an RK cell formatted as HH:MM:SS
+			if got := wb.GetSheet(0).Row(0).Col(1); got != tt.want {
+				t.Errorf("B1 = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestNumberFormats keeps number cells with custom number formats as plain numbers,
+// and renders those with date or time formats as dates.
+func TestNumberFormats(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format string
+		value  float64
+		want   string
+	}{
+		{"0", 12345, "12345"},
+		{"0.0", 12345, "12345"},
+		{"0.0%", 0.125, "0.125"},
+		{"0.000E+00", 12345, "12345"},
+		{"[Red]0", 12345, "12345"},
+		{`"$"0`, 12345, "12345"},
+		{"General", 0.125, "0.125"},
+		{"yyyy-mm-dd", 43831, "01.01.2020"},
+		{"ДД.ММ.ГГГГ", 43831, "01.01.2020"},
+		{"[h]:mm", 0.125, "30.12.1899"},
+	}
+
+	for _, tt := range tests {
+		wb := &WorkBook{
+			Xfs:     []st_xf_data{&Xf8{Format: 164}},
+			Formats: map[uint16]*Format{164: {str: tt.format}},
+		}
+
+		if got := (&NumberCol{Float: tt.value}).String(wb)[0]; got != tt.want {
+			t.Errorf("NUMBER %v in %q = %q, want %q", tt.value, tt.format, got, tt.want)
+		}
+
+		// integers also come as RK values
+		if tt.value == float64(int32(tt.value)) {
+			rk := RK(uint32(int32(tt.value))<<2 | 2)
+			if got := (&XfRk{Rk: rk}).String(wb); got != tt.want {
+				t.Errorf("RK %v in %q = %q, want %q", tt.value, tt.format, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestNumberDateOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts *Options
+		want string
+	}{
+		{"default", nil, "30.12.1899"},
+		{"time layout", &Options{TimeLayout: "15:04:05"}, "18:00:00"},
+		{"location", &Options{TimeLayout: "15:04 MST", Location: time.FixedZone("UTC+3", 3*60*60)}, "18:00 UTC+3"},
+		{"raw serials", &Options{RawSerials: true, TimeLayout: "15:04:05"}, "0.75"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			wb, err := OpenContext(context.Background(), "testdata/float.xls", tt.opts)
+			if err != nil {
+				t.Fatalf("failed to open XLS file: %v", err)
+			}
+
+			// XF 22 of float.xls has the custom format HH:MM:SS
+			cell := &NumberCol{Index: 22, Float: 0.75}
+			if got := cell.String(wb)[0]; got != tt.want {
+				t.Errorf("NUMBER cell = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertSerial(t *testing.T) {
+	t.Parallel()
+
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	wb := &WorkBook{opts: Options{Location: loc}}
+
+	want := time.Date(2020, 1, 1, 12, 0, 0, 0, loc)
+	if got := wb.ConvertSerial(43831.5); !got.Equal(want) {
+		t.Errorf("ConvertSerial = %v, want %v", got, want)
+	}
+
+	wb.dateMode = 1
+	if !wb.Date1904() {
+		t.Error("Date1904 = false, want true")
+	}
+
+	want = time.Date(1904, 1, 2, 0, 0, 0, 0, loc)
+	if got := wb.ConvertSerial(1); !got.Equal(want) {
+		t.Errorf("ConvertSerial in 1904 mode = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,119 @@
+//nolint:mnd
+package xls
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+)
+
+// Dimensions is the used range of a sheet, zero-based and inclusive.
+// For an empty sheet Empty is set and LastRow and LastCol are -1.
+type Dimensions struct {
+	FirstRow, LastRow int
+	FirstCol, LastCol int
+	Empty             bool
+}
+
+// emptyDimensions is the used range of a sheet without cells.
+var emptyDimensions = Dimensions{LastRow: -1, LastCol: -1, Empty: true}
+
+// Dimensions returns the used range of the sheet from its DIMENSIONS record, which is read
+// from the sheet header without decoding any cell. Sheets written without the record are
+// parsed, and the range is computed from their cells.
+func (w *WorkSheet) Dimensions() (Dimensions, error) {
+	if !w.Kind.hasCells() {
+		return emptyDimensions, nil
+	}
+
+	if w.dims == nil && !w.parsed {
+		if err := w.readDimensions(); err != nil {
+			return Dimensions{}, err
+		}
+	}
+
+	if w.dims != nil {
+		return *w.dims, nil
+	}
+
+	if !w.parsed {
+		if err := w.wb.prepareSheet(context.Background(), w); err != nil {
+			return Dimensions{}, err
+		}
+	}
+
+	return w.usedRange(), nil
+}
+
+// readDimensions scans the sheet header for the DIMENSIONS record, which precedes the rows.
+func (w *WorkSheet) readDimensions() error {
+	records, err := acquireRecordReader(w.wb.rs, int64(w.bs.Filepos))
+	if err != nil {
+		return fmt.Errorf("xls: dimensions: %w", err)
+	}
+	defer records.release()
+
+	for {
+		b, data, _, err := records.next()
+		if err != nil {
+			return nil
+		}
+
+		switch b.ID {
+		case 0x200: // DIMENSIONS
+			w.setDimensions(data)
+
+			return nil
+		case 0x208, 0xa: // ROW or EOF: there is no DIMENSIONS record
+			return nil
+		}
+	}
+}
+
+// setDimensions decodes a DIMENSIONS record. Its last row and column are exclusive.
+func (w *WorkSheet) setDimensions(data []byte) {
+	var firstRow, lastRow, firstCol, lastCol int
+
+	switch {
+	case w.wb.Is5ver && len(data) >= 8:
+		firstRow, lastRow = int(binary.LittleEndian.Uint16(data)), int(binary.LittleEndian.Uint16(data[2:]))
+		firstCol, lastCol = int(binary.LittleEndian.Uint16(data[4:])), int(binary.LittleEndian.Uint16(data[6:]))
+	case len(data) >= 12:
+		firstRow, lastRow = int(binary.LittleEndian.Uint32(data)), int(binary.LittleEndian.Uint32(data[4:]))
+		firstCol, lastCol = int(binary.LittleEndian.Uint16(data[8:])), int(binary.LittleEndian.Uint16(data[10:]))
+	default:
+		return
+	}
+
+	if lastRow <= firstRow || lastCol <= firstCol {
+		empty := emptyDimensions
+		w.dims = &empty
+
+		return
+	}
+
+	w.dims = &Dimensions{FirstRow: firstRow, LastRow: lastRow - 1, FirstCol: firstCol, LastCol: lastCol - 1}
+}
+
+// usedRange computes the range covered by the cells of a parsed sheet.
+func (w *WorkSheet) usedRange() Dimensions {
+	dims := Dimensions{FirstRow: maxRowIndex + 1, LastRow: -1, FirstCol: maxRowIndex + 1, LastCol: -1}
+
+	for index, row := range w.rows {
+		for i, ch := range row.cells {
+			if ch == nil {
+				continue
+			}
+
+			col := int(row.first) + i
+			dims.FirstRow, dims.LastRow = min(dims.FirstRow, int(index)), max(dims.LastRow, int(index))
+			dims.FirstCol, dims.LastCol = min(dims.FirstCol, col), max(dims.LastCol, col)
+		}
+	}
+
+	if dims.LastRow < 0 {
+		return emptyDimensions
+	}
+
+	return dims
+}
@@ -0,0 +1,59 @@
+package xls
+
+import (
+	"testing"
+)
+
+// TestDimensions checks that the DIMENSIONS record, read without parsing the sheet,
+// matches the range covered by the parsed cells.
+func TestDimensions(t *testing.T) {
+	t.Parallel()
+
+	// times.xls is left out: its DIMENSIONS also covers a formatted row without cells
+	for _, name := range []string{"bigtable", "float", "issue47", "negatives", "superstore"} {
+		name := name
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			wb, err := Open("testdata/" + name + ".xls")
+			if err != nil {
+				t.Fatalf("failed to open XLS file: %v", err)
+			}
+
+			sheet := wb.Sheet(0)
+
+			dims, err := sheet.Dimensions()
+			if err != nil {
+				t.Fatalf("Dimensions failed: %v", err)
+			}
+
+			if sheet.parsed {
+				t.Error("Dimensions parsed the sheet")
+			}
+
+			if got := wb.GetSheet(0).usedRange(); got != dims {
+				t.Errorf("Dimensions() = %+v, cells cover %+v", dims, got)
+			}
+		})
+	}
+}
+
+func TestEmptyDimensions(t *testing.T) {
+	t.Parallel()
+
+	sheet := &WorkSheet{wb: new(WorkBook), rows: make(map[uint16]*Row), parsed: true}
+	if dims, err := sheet.Dimensions(); err != nil || dims != emptyDimensions {
+		t.Errorf("sheet without DIMENSIONS: %+v, %v; want empty", dims, err)
+	}
+
+	sheet.setDimensions(make([]byte, 14))
+	if dims, err := sheet.Dimensions(); err != nil || !dims.Empty || dims.LastRow != -1 {
+		t.Errorf("empty DIMENSIONS: %+v, %v; want empty", dims, err)
+	}
+
+	sheet.setDimensions([]byte{2, 0, 0, 0, 3, 0, 0, 0, 1, 0, 4, 0, 0, 0})
+	if dims, _ := sheet.Dimensions(); dims != (Dimensions{FirstRow: 2, LastRow: 2, FirstCol: 1, LastCol: 3}) {
+		t.Errorf("one row: %+v", dims)
+	}
+}
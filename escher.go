@@ -0,0 +1,217 @@
+//nolint:mnd
+package xls
+
+import (
+	"encoding/binary"
+	"unicode/utf16"
+)
+
+// Office Drawing (Escher) record types used by the parser.
+const (
+	escherDggContainer    = 0xF000
+	escherBStoreContainer = 0xF001
+	escherDgContainer     = 0xF002
+	escherSpgrContainer   = 0xF003
+	escherSpContainer     = 0xF004
+	escherBSE             = 0xF007
+	escherFOPT            = 0xF00B
+	escherClientAnchor    = 0xF010
+	escherClientData      = 0xF011
+)
+
+// escherHeader is the header of an Office Drawing record.
+type escherHeader struct {
+	instance uint16
+	typ      uint16
+	size     uint32
+}
+
+// escherRecords splits data into Office Drawing records and calls fn with the header and body of each.
+// A record whose body runs past the end of data is cut short.
+func escherRecords(data []byte, fn func(h escherHeader, body []byte)) {
+	for len(data) >= 8 {
+		h := escherHeader{
+			instance: binary.LittleEndian.Uint16(data) >> 4,
+			typ:      binary.LittleEndian.Uint16(data[2:]),
+			size:     binary.LittleEndian.Uint32(data[4:]),
+		}
+
+		body := data[8:]
+		if uint64(h.size) < uint64(len(body)) {
+			body = body[:h.size]
+		}
+
+		fn(h, body)
+
+		data = data[8+len(body):]
+	}
+}
+
+// handleDrawingGroup collects the MSODRAWINGGROUP records, which hold the drawing group
+// container with the pictures of the workbook. The CONTINUE records after them belong to it too.
+func handleDrawingGroup(wb *WorkBook, data []byte) {
+	wb.drawingGroup = append(wb.drawingGroup, data...)
+	wb.drawingOpen = true
+}
+
+// blipStore returns the BSE records of the drawing group, in the order the shapes refer to them.
+// The entries are sliced from the drawing group, which is decoded on the first call.
+func (wb *WorkBook) blipStore() [][]byte {
+	if wb.blips != nil || len(wb.drawingGroup) == 0 {
+		return wb.blips
+	}
+
+	wb.blips = [][]byte{}
+
+	escherRecords(wb.drawingGroup, func(h escherHeader, body []byte) {
+		if h.typ != escherDggContainer {
+			return
+		}
+
+		escherRecords(body, func(h escherHeader, body []byte) {
+			if h.typ != escherBStoreContainer {
+				return
+			}
+
+			escherRecords(body, func(h escherHeader, body []byte) {
+				if h.typ == escherBSE {
+					wb.blips = append(wb.blips, body)
+				} else {
+					// keep the numbering of the entries the shapes refer to
+					wb.blips = append(wb.blips, nil)
+				}
+			})
+		})
+	})
+
+	return wb.blips
+}
+
+// escherShape is the part of a shape container used to find pictures.
+type escherShape struct {
+	pib         int // 1-based index of the picture in the BLIP store, 0 if none
+	name        string
+	description string
+	anchor      []byte // the OfficeArtClientAnchorSheet, if the shape has one
+	clientData  bool   // the shape has an OBJ record
+}
+
+// escherShapes returns the shapes of a sheet's drawing container, in drawing order.
+// Shapes inside a group without an anchor of their own take the anchor of the group.
+func escherShapes(drawing []byte) []escherShape {
+	var shapes []escherShape
+
+	escherRecords(drawing, func(h escherHeader, body []byte) {
+		shapes = appendShapes(shapes, h, body, nil)
+	})
+
+	return shapes
+}
+
+// appendShapes appends the shapes of one record of a drawing container.
+func appendShapes(shapes []escherShape, h escherHeader, body []byte, groupAnchor []byte) []escherShape {
+	switch h.typ {
+	case escherDgContainer:
+		escherRecords(body, func(h escherHeader, body []byte) {
+			shapes = appendShapes(shapes, h, body, groupAnchor)
+		})
+	case escherSpgrContainer:
+		// the first shape of a group describes the group itself
+		first := true
+
+		escherRecords(body, func(h escherHeader, body []byte) {
+			if first && h.typ == escherSpContainer {
+				first = false
+
+				s := readEscherShape(body)
+				if s.anchor != nil {
+					groupAnchor = s.anchor
+				}
+
+				// keep the groups with an OBJ record, so the shapes line up with the OBJ records
+				if s.clientData {
+					shapes = append(shapes, s)
+				}
+
+				return
+			}
+
+			shapes = appendShapes(shapes, h, body, groupAnchor)
+		})
+	case escherSpContainer:
+		s := readEscherShape(body)
+		if s.anchor == nil {
+			s.anchor = groupAnchor
+		}
+
+		shapes = append(shapes, s)
+	}
+
+	return shapes
+}
+
+// readEscherShape decodes the properties and the anchor of a shape container.
+func readEscherShape(data []byte) escherShape {
+	var s escherShape
+
+	escherRecords(data, func(h escherHeader, body []byte) {
+		switch h.typ {
+		case escherFOPT:
+			s.readProperties(int(h.instance), body)
+		case escherClientAnchor:
+			if len(body) >= 18 {
+				s.anchor = body
+			}
+		case escherClientData:
+			s.clientData = true
+		}
+	})
+
+	return s
+}
+
+// readProperties decodes the FOPT properties of a shape: count entries of a property id
+// and a value, followed by the data of the complex properties in the same order.
+func (s *escherShape) readProperties(count int, data []byte) {
+	if 6*count > len(data) {
+		return
+	}
+
+	complexData := data[6*count:]
+
+	for i := 0; i < count; i++ {
+		opid := binary.LittleEndian.Uint16(data[6*i:])
+		op := binary.LittleEndian.Uint32(data[6*i+2:])
+
+		var value []byte
+		if opid&0x8000 != 0 {
+			n := int(min(op, uint32(len(complexData))))
+			value, complexData = complexData[:n], complexData[n:]
+		}
+
+		switch opid & 0x3FFF {
+		case 0x104: // pib
+			s.pib = int(op)
+		case 0x380: // wzName
+			s.name = escherString(value)
+		case 0x381: // wzDescription
+			s.description = escherString(value)
+		}
+	}
+}
+
+// escherString decodes a null-terminated UTF-16 string property.
+func escherString(data []byte) string {
+	u16 := make([]uint16, 0, len(data)/2)
+
+	for i := 0; i+1 < len(data); i += 2 {
+		c := binary.LittleEndian.Uint16(data[i:])
+		if c == 0 {
+			break
+		}
+
+		u16 = append(u16, c)
+	}
+
+	return string(utf16.Decode(u16))
+}
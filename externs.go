@@ -0,0 +1,128 @@
+//nolint:mnd
+package xls
+
+import (
+	"encoding/binary"
+)
+
+// supBook is a SUPBOOK record: a workbook that 3D references of formulas point into.
+type supBook struct {
+	self  bool // the workbook itself
+	addIn bool // the functions of add-ins
+	// path is the encoded file name of an external workbook, or the application and topic of a DDE or OLE link.
+	path   string
+	sheets []string
+	names  []string // EXTERNNAME records: defined names, add-in functions or DDE and OLE items
+	ole    bool     // an EXTERNNAME is an OLE link
+}
+
+// externSheet is an XTI entry of the EXTERNSHEET record, the target of a 3D reference.
+type externSheet struct {
+	supBook     int
+	first, last int // sheet indexes in the supporting workbook; negative for workbook-level references
+}
+
+// handleSupBook decodes a SUPBOOK record: the number of sheets and either a marker for the
+// workbook itself or for add-ins, or the encoded path followed by the sheet names.
+func handleSupBook(wb *WorkBook, data []byte) {
+	if len(data) < 4 || wb.Is5ver {
+		return
+	}
+
+	count, cch := binary.LittleEndian.Uint16(data), binary.LittleEndian.Uint16(data[2:])
+
+	var b supBook
+
+	switch cch {
+	case 0x0401:
+		b.self = true
+	case 0x3A01:
+		b.addIn = true
+	default:
+		wb.strReader.Reset(data[4:])
+
+		var err error
+		if b.path, err = wb.getString(&wb.strReader, cch); err != nil {
+			break
+		}
+
+		for i := 0; i < int(count); i++ {
+			var size uint16
+			if binary.Read(&wb.strReader, binary.LittleEndian, &size) != nil {
+				break
+			}
+
+			name, err := wb.getString(&wb.strReader, size)
+			if err != nil {
+				break
+			}
+
+			b.sheets = append(b.sheets, name)
+		}
+	}
+
+	wb.supBooks = append(wb.supBooks, b)
+}
+
+// handleExternSheet decodes an EXTERNSHEET record: a count and as many XTI entries of
+// a SUPBOOK index and the first and last sheet.
+func handleExternSheet(wb *WorkBook, data []byte) {
+	if len(data) < 2 || wb.Is5ver {
+		return
+	}
+
+	count := int(binary.LittleEndian.Uint16(data))
+
+	for i := 0; i < count && 2+6*i+6 <= len(data); i++ {
+		xti := data[2+6*i:]
+		wb.externSheets = append(wb.externSheets, externSheet{
+			supBook: int(binary.LittleEndian.Uint16(xti)),
+			first:   int(int16(binary.LittleEndian.Uint16(xti[2:]))),
+			last:    int(int16(binary.LittleEndian.Uint16(xti[4:]))),
+		})
+	}
+}
+
+// handleExternName decodes an EXTERNNAME record of the last SUPBOOK: flags, four bytes that
+// depend on the kind of name, and the name with an 8-bit length.
+func handleExternName(wb *WorkBook, data []byte) {
+	if len(data) < 7 || len(wb.supBooks) == 0 || wb.Is5ver {
+		return
+	}
+
+	b := &wb.supBooks[len(wb.supBooks)-1]
+
+	// fOle, fOleLink
+	if binary.LittleEndian.Uint16(data)&0x18 != 0 {
+		b.ole = true
+	}
+
+	wb.strReader.Reset(data[7:])
+	if name, err := wb.getString(&wb.strReader, uint16(data[6])); err == nil {
+		b.names = append(b.names, name)
+	}
+}
+
+// externSheetName returns the name of the sheet a 3D reference with the given EXTERNSHEET
+// index points to, with the file name in brackets for other workbooks, or "" if it is unknown.
+func (wb *WorkBook) externSheetName(ixti uint16) string {
+	if int(ixti) >= len(wb.externSheets) {
+		return ""
+	}
+
+	xti := wb.externSheets[ixti]
+	if xti.supBook >= len(wb.supBooks) || xti.first < 0 {
+		return ""
+	}
+
+	b := wb.supBooks[xti.supBook]
+
+	switch {
+	case b.self && xti.first < len(wb.sheets):
+		return wb.sheets[xti.first].Name
+	case !b.self && xti.first < len(b.sheets):
+		return "[" + b.path + "]" + b.sheets[xti.first]
+	}
+
+	return ""
+}
@@ -17,3 +17,18 @@ type Font struct {
 	Info *FontInfo
 	Name string
 }
+
+// font returns the font with the given FONT record index, or nil if there is none.
+// Index 4 is never written, so the indexes above it are one past their position in Fonts.
+func (wb *WorkBook) font(idx uint16) *Font {
+	i := int(idx)
+	if i >= 4 {
+		i--
+	}
+
+	if i >= len(wb.Fonts) {
+		return nil
+	}
+
+	return &wb.Fonts[i]
+}
@@ -0,0 +1,81 @@
+//nolint:mnd
+package xls
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Thresholds of Excel's "General" format, independent of the column width:
+// at most 15 significant digits are shown, and numbers whose magnitude needs
+// more than 15 integer digits, or that are smaller than 1E-9, use scientific notation.
+const (
+	generalDigits      = 15
+	generalMaxExponent = 14
+	generalMinExponent = -9
+)
+
+// formatGeneral renders a number the way Excel displays it in a cell formatted as "General",
+// e.g. 0.1+0.2 as "0.3" and 1e21 as "1E+21".
+func formatGeneral(f float64) string {
+	if f == 0 {
+		return "0"
+	}
+
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "#NUM!"
+	}
+
+	// Round to 15 significant digits: "d.ddddddddddddddde±XX"
+	sci := strconv.FormatFloat(f, 'e', generalDigits-1, 64)
+
+	mantissa, exponentStr, _ := strings.Cut(sci, "e")
+	exponent, _ := strconv.Atoi(exponentStr)
+
+	negative := strings.HasPrefix(mantissa, "-")
+	digits := strings.TrimRight(strings.Replace(strings.TrimPrefix(mantissa, "-"), ".", "", 1), "0")
+
+	var sb strings.Builder
+	if negative {
+		sb.WriteByte('-')
+	}
+
+	switch {
+	case exponent > generalMaxExponent || exponent < generalMinExponent:
+		sb.WriteByte(digits[0])
+
+		if len(digits) > 1 {
+			sb.WriteByte('.')
+			sb.WriteString(digits[1:])
+		}
+
+		sb.WriteByte('E')
+
+		if exponent < 0 {
+			sb.WriteByte('-')
+			exponent = -exponent
+		} else {
+			sb.WriteByte('+')
+		}
+
+		if exponent < 10 {
+			sb.WriteByte('0')
+		}
+
+		sb.WriteString(strconv.Itoa(exponent))
+	case exponent < 0:
+		sb.WriteString("0.")
+		sb.WriteString(strings.Repeat("0", -exponent-1))
+		sb.WriteString(digits)
+	case len(digits) <= exponent+1:
+		sb.WriteString(digits)
+		sb.WriteString(strings.Repeat("0", exponent+1-len(digits)))
+	default:
+		sb.WriteString(digits[:exponent+1])
+		sb.WriteByte('.')
+		sb.WriteString(digits[exponent+1:])
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,83 @@
+package xls
+
+import (
+	"testing"
+
+	"github.com/tealeg/xlsx"
+)
+
+func TestFormatGeneral(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{0, "0"},
+		{1, "1"},
+		{-42, "-42"},
+		{0.1 + 0.2, "0.3"},
+		{1.0 / 3, "0.333333333333333"},
+		{2.0 / 3, "0.666666666666667"},
+		{-3244847.3000000003, "-3244847.3"},
+		{123456789012345, "123456789012345"},
+		{1234567890123456, "1.23456789012346E+15"},
+		{1e15, "1E+15"},
+		{1e21, "1E+21"},
+		{-1.5e100, "-1.5E+100"},
+		{0.000000001, "0.000000001"},
+		{1.25e-10, "1.25E-10"},
+		{1e-300, "1E-300"},
+		{99999999999999.99, "100000000000000"}, // rounds to 15 digits
+	}
+
+	for _, tt := range tests {
+		if got := formatGeneral(tt.value); got != tt.want {
+			t.Errorf("formatGeneral(%v): got %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+// TestGeneralFixtures compares numbers in General-formatted cells with the values
+// Excel stored for the same cells in the .xlsx version of each fixture.
+func TestGeneralFixtures(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"float", "negatives"} {
+		xlsFile, err := Open("testdata/" + name + ".xls")
+		if err != nil {
+			t.Fatalf("failed to open XLS file: %v", err)
+		}
+
+		xlsxFile, err := xlsx.OpenFile("testdata/" + name + ".xlsx")
+		if err != nil {
+			t.Fatalf("failed to open XLSX file: %v", err)
+		}
+
+		sheet := xlsFile.GetSheet(0)
+		checked := 0
+
+		for rowIdx, xlsxRow := range xlsxFile.Sheets[0].Rows {
+			for colIdx, cell := range xlsxRow.Cells {
+				if cell.Type() != xlsx.CellTypeNumeric || cell.Value == "" {
+					continue
+				}
+
+				row := sheet.Row(rowIdx)
+				if row == nil {
+					t.Fatalf("%s: row %d missing", name, rowIdx)
+				}
+
+				if got := row.Col(colIdx); got != cell.Value {
+					t.Errorf("%s: row %d, col %d: got %q, want %q", name, rowIdx, colIdx, got, cell.Value)
+				}
+
+				checked++
+			}
+		}
+
+		if checked == 0 {
+			t.Errorf("%s: no numeric cells checked", name)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package xls
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrLimitExceeded is matched (via errors.Is) by every *LimitError.
+var ErrLimitExceeded = errors.New("xls: resource limit exceeded")
+
+// Limits caps the resources a single workbook may consume while it is parsed,
+// to defend against crafted files whose headers announce huge counts.
+// A zero field means "no limit".
+type Limits struct {
+	// MaxSSTStrings caps the number of entries in the shared string table.
+	MaxSSTStrings int
+	// MaxCells caps the number of cells stored per sheet.
+	MaxCells int
+	// MaxRowsPerSheet caps the number of rows stored per sheet.
+	MaxRowsPerSheet int
+	// MaxStringLength caps the length, in characters, of any single string.
+	MaxStringLength int
+	// MaxDecodedBytes caps the total record payload decoded for the workbook,
+	// summed over the workbook globals and every sheet parse.
+	MaxDecodedBytes int64
+}
+
+// LimitError reports which limit a workbook exceeded.
+type LimitError struct {
+	// Limit names the exceeded limit, e.g. "MaxSSTStrings".
+	Limit string
+	// Max is the configured maximum.
+	Max int64
+	// Value is the value that exceeded it.
+	Value int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("xls: %s exceeded: %d > %d", e.Limit, e.Value, e.Max)
+}
+
+// Is makes errors.Is(err, ErrLimitExceeded) true for any *LimitError.
+func (e *LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// checkLimit records a LimitError if value is above a non-zero max.
+// Only the first violation of a parse is kept; the record loops stop on it.
+func (wb *WorkBook) checkLimit(limit string, value, max int64) error {
+	if max <= 0 || value <= max {
+		return nil
+	}
+
+	if wb.limitErr == nil {
+		wb.limitErr = &LimitError{Limit: limit, Max: max, Value: value}
+	}
+
+	return wb.limitErr
+}
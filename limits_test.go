@@ -0,0 +1,84 @@
+package xls
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	t.Parallel()
+
+	const filePath = "testdata/bigtable.xls"
+
+	tests := []struct {
+		name   string
+		limits Limits
+		open   bool // limit is hit while opening rather than while parsing the sheet
+	}{
+		{"MaxSSTStrings", Limits{MaxSSTStrings: 100}, true},
+		{"MaxDecodedBytes", Limits{MaxDecodedBytes: 4096}, true},
+		{"MaxStringLength", Limits{MaxStringLength: 5}, true},
+		{"MaxRowsPerSheet", Limits{MaxRowsPerSheet: 1000}, false},
+		{"MaxCells", Limits{MaxCells: 1000}, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			workBook, err := OpenContext(context.Background(), filePath, &Options{Limits: tt.limits})
+			if !tt.open {
+				if err != nil {
+					t.Fatalf("OpenContext failed: %v", err)
+				}
+
+				_, err = workBook.GetSheetContext(context.Background(), 0)
+			}
+
+			if !errors.Is(err, ErrLimitExceeded) {
+				t.Fatalf("got error %v, want ErrLimitExceeded", err)
+			}
+
+			var limitErr *LimitError
+			if !errors.As(err, &limitErr) || limitErr.Limit != tt.name {
+				t.Fatalf("got error %v, want a *LimitError for %s", err, tt.name)
+			}
+		})
+	}
+
+	// Generous limits must not get in the way
+	workBook, err := OpenContext(context.Background(), filePath, &Options{Limits: Limits{
+		MaxSSTStrings:   1 << 20,
+		MaxCells:        1 << 20,
+		MaxRowsPerSheet: 1 << 16,
+		MaxStringLength: 1 << 12,
+		MaxDecodedBytes: 1 << 26,
+	}})
+	if err != nil {
+		t.Fatalf("OpenContext failed: %v", err)
+	}
+
+	if _, err := workBook.GetSheetContext(context.Background(), 0); err != nil {
+		t.Fatalf("GetSheetContext failed: %v", err)
+	}
+}
+
+// TestParseLimit checks that ParseContext returns the limit error instead of dropping it.
+func TestParseLimit(t *testing.T) {
+	t.Parallel()
+
+	stream := bytes.Join([][]byte{
+		biffRecord(0x809, []byte{0, 6, 5, 0}, make([]byte, 12)),
+		biffRecord(0x42, []byte{0xE4, 0x04}),
+		biffRecord(0x0A),
+	}, nil)
+
+	workBook := &WorkBook{opts: Options{Limits: Limits{MaxDecodedBytes: 8}}}
+	if err := workBook.ParseContext(context.Background(), bytes.NewReader(stream)); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("ParseContext: got error %v, want ErrLimitExceeded", err)
+	}
+}
@@ -0,0 +1,254 @@
+package xls
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+)
+
+// ErrUnknownLocale is matched (via errors.Is) when Options.Locale names an unsupported locale.
+var ErrUnknownLocale = errors.New("xls: unknown locale")
+
+// locale holds what a built-in number format looks like in one Excel locale.
+// Built-in formats are stored in the file by id only; their patterns come from the locale of the reader.
+type locale struct {
+	decimal  string
+	group    string
+	currency string
+	months   [12]string
+	monthsAb [12]string
+	days     [7]string // starting on Sunday
+	daysAb   [7]string
+	am, pm   string
+	japanese bool // era tokens "g" and "e" use the Japanese calendar
+	formats  map[uint16]string
+}
+
+// locales lists the supported Options.Locale tags.
+var locales = map[string]*locale{
+	"en-US": {
+		decimal:  ".",
+		group:    ",",
+		currency: "$",
+		months:   [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		monthsAb: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		days:     [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		daysAb:   [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+		am:       "AM",
+		pm:       "PM",
+		formats: builtinFormats(
+			westernDates("m/d/yyyy", "d-mmm-yy", "d-mmm", "mmm-yy", "h:mm", "h:mm:ss", "m/d/yyyy h:mm"),
+			map[uint16]string{
+				5:  `"$"#,##0_);\("$"#,##0\)`,
+				6:  `"$"#,##0_);[Red]\("$"#,##0\)`,
+				7:  `"$"#,##0.00_);\("$"#,##0.00\)`,
+				8:  `"$"#,##0.00_);[Red]\("$"#,##0.00\)`,
+				37: `#,##0_);\(#,##0\)`,
+				38: `#,##0_);[Red]\(#,##0\)`,
+				39: `#,##0.00_);\(#,##0.00\)`,
+				40: `#,##0.00_);[Red]\(#,##0.00\)`,
+				41: `_(* #,##0_);_(* \(#,##0\);_(* "-"_);_(@_)`,
+				42: `_("$"* #,##0_);_("$"* \(#,##0\);_("$"* "-"_);_(@_)`,
+				43: `_(* #,##0.00_);_(* \(#,##0.00\);_(* "-"??_);_(@_)`,
+				44: `_("$"* #,##0.00_);_("$"* \(#,##0.00\);_("$"* "-"??_);_(@_)`,
+			},
+		),
+	},
+	"de-DE": {
+		decimal:  ",",
+		group:    ".",
+		currency: "€",
+		months:   [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		monthsAb: [12]string{"Jan", "Feb", "Mär", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Okt", "Nov", "Dez"},
+		days:     [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		daysAb:   [7]string{"So", "Mo", "Di", "Mi", "Do", "Fr", "Sa"},
+		am:       "AM",
+		pm:       "PM",
+		formats: builtinFormats(
+			westernDates("dd.mm.yyyy", "dd. mmm yy", "dd. mmm", "mmm yy", "hh:mm", "hh:mm:ss", "dd.mm.yyyy hh:mm"),
+			suffixCurrency("€"),
+		),
+	},
+	"ru-RU": {
+		decimal:  ",",
+		group:    "\u00a0",
+		currency: "₽",
+		months:   [12]string{"Январь", "Февраль", "Март", "Апрель", "Май", "Июнь", "Июль", "Август", "Сентябрь", "Октябрь", "Ноябрь", "Декабрь"},
+		monthsAb: [12]string{"янв", "фев", "мар", "апр", "май", "июн", "июл", "авг", "сен", "окт", "ноя", "дек"},
+		days:     [7]string{"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"},
+		daysAb:   [7]string{"Вс", "Пн", "Вт", "Ср", "Чт", "Пт", "Сб"},
+		am:       "AM",
+		pm:       "PM",
+		formats: builtinFormats(
+			westernDates("dd.mm.yyyy", "dd.mmm.yy", "dd.mmm", "mmm.yy", "h:mm", "h:mm:ss", "dd.mm.yyyy h:mm"),
+			suffixCurrency("₽"),
+		),
+	},
+	"ja-JP": {
+		decimal:  ".",
+		group:    ",",
+		currency: "¥",
+		months:   [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		monthsAb: [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		days:     [7]string{"日曜日", "月曜日", "火曜日", "水曜日", "木曜日", "金曜日", "土曜日"},
+		daysAb:   [7]string{"日", "月", "火", "水", "木", "金", "土"},
+		am:       "午前",
+		pm:       "午後",
+		japanese: true,
+		formats: builtinFormats(
+			westernDates("yyyy/m/d", "d-mmm-yy", "d-mmm", "mmm-yy", "h:mm", "h:mm:ss", "yyyy/m/d h:mm"),
+			prefixCurrency("¥"),
+			cjkDates(map[uint16]string{
+				27: `[$-411]ge.m.d`,
+				28: `[$-411]ggge"年"m"月"d"日"`,
+				30: `m/d/yy`,
+				31: `yyyy"年"m"月"d"日"`,
+				32: `h"時"mm"分"`,
+				33: `h"時"mm"分"ss"秒"`,
+				34: `yyyy"年"m"月"`,
+				35: `m"月"d"日"`,
+			}, 28, 27, 27, 28, 34, 35, 28, 34, 35, 27, 28),
+		),
+	},
+	"zh-CN": {
+		decimal:  ".",
+		group:    ",",
+		currency: "¥",
+		months:   [12]string{"一月", "二月", "三月", "四月", "五月", "六月", "七月", "八月", "九月", "十月", "十一月", "十二月"},
+		monthsAb: [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		days:     [7]string{"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"},
+		daysAb:   [7]string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"},
+		am:       "上午",
+		pm:       "下午",
+		formats: builtinFormats(
+			westernDates("yyyy/m/d", "d-mmm-yy", "d-mmm", "mmm-yy", "h:mm", "h:mm:ss", "yyyy/m/d h:mm"),
+			prefixCurrency("¥"),
+			cjkDates(map[uint16]string{
+				27: `yyyy"年"m"月"`,
+				28: `m"月"d"日"`,
+				30: `m-d-yy`,
+				31: `yyyy"年"m"月"d"日"`,
+				32: `h"时"mm"分"`,
+				33: `h"时"mm"分"ss"秒"`,
+				34: `上午/下午h"时"mm"分"`,
+				35: `上午/下午h"时"mm"分"ss"秒"`,
+			}, 28, 27, 27, 28, 34, 35, 28, 34, 35, 27, 28),
+		),
+	},
+}
+
+// lookupLocale returns the locale for an Options.Locale tag, or nil for the empty tag.
+func lookupLocale(tag string) (*locale, error) {
+	if tag == "" {
+		return nil, nil
+	}
+
+	if l, ok := locales[tag]; ok {
+		return l, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnknownLocale, tag)
+}
+
+// builtinFormats merges the locale-specific pattern sets over the formats that are the same everywhere.
+func builtinFormats(sets ...map[uint16]string) map[uint16]string {
+	formats := map[uint16]string{
+		1:  "0",
+		2:  "0.00",
+		3:  "#,##0",
+		4:  "#,##0.00",
+		9:  "0%",
+		10: "0.00%",
+		11: "0.00E+00",
+		12: "# ?/?",
+		13: "# ??/??",
+		18: "h:mm AM/PM",
+		19: "h:mm:ss AM/PM",
+		45: "mm:ss",
+		46: "[h]:mm:ss",
+		47: "mm:ss.0",
+		48: "##0.0E+0",
+		49: "@",
+	}
+
+	for _, set := range sets {
+		maps.Copy(formats, set)
+	}
+
+	return formats
+}
+
+// westernDates returns the date formats of a locale without CJK formats,
+// where the ids reserved for CJK formats display like the short date (id 14).
+func westernDates(short, dayMonthYear, dayMonth, monthYear, hourMinute, hourMinuteSecond, dateTime string) map[uint16]string {
+	formats := map[uint16]string{
+		14: short,
+		15: dayMonthYear,
+		16: dayMonth,
+		17: monthYear,
+		20: hourMinute,
+		21: hourMinuteSecond,
+		22: dateTime,
+	}
+
+	for id := uint16(27); id <= 36; id++ {
+		formats[id] = short
+	}
+
+	for id := uint16(50); id <= 58; id++ {
+		formats[id] = short
+	}
+
+	return formats
+}
+
+// cjkDates completes the CJK formats 27–35 with their aliases: id 29 and 36 first,
+// then 50–58 in order, each given as the id it displays like.
+func cjkDates(formats map[uint16]string, aliases ...uint16) map[uint16]string {
+	ids := []uint16{29, 36, 50, 51, 52, 53, 54, 55, 56, 57, 58}
+	for i, id := range ids {
+		formats[id] = formats[aliases[i]]
+	}
+
+	return formats
+}
+
+// prefixCurrency returns the currency and accounting formats of a locale writing the symbol first.
+func prefixCurrency(symbol string) map[uint16]string {
+	q := `"` + symbol + `"`
+
+	return map[uint16]string{
+		5:  q + `#,##0;` + q + `\-#,##0`,
+		6:  q + `#,##0;[Red]` + q + `\-#,##0`,
+		7:  q + `#,##0.00;` + q + `\-#,##0.00`,
+		8:  q + `#,##0.00;[Red]` + q + `\-#,##0.00`,
+		37: `#,##0;\-#,##0`,
+		38: `#,##0;[Red]\-#,##0`,
+		39: `#,##0.00;\-#,##0.00`,
+		40: `#,##0.00;[Red]\-#,##0.00`,
+		41: `_ * #,##0_ ;_ * \-#,##0_ ;_ * "-"_ ;_ @_ `,
+		42: `_ ` + q + `* #,##0_ ;_ ` + q + `* \-#,##0_ ;_ ` + q + `* "-"_ ;_ @_ `,
+		43: `_ * #,##0.00_ ;_ * \-#,##0.00_ ;_ * "-"??_ ;_ @_ `,
+		44: `_ ` + q + `* #,##0.00_ ;_ ` + q + `* \-#,##0.00_ ;_ ` + q + `* "-"??_ ;_ @_ `,
+	}
+}
+
+// suffixCurrency returns the currency and accounting formats of a locale writing the symbol last.
+func suffixCurrency(symbol string) map[uint16]string {
+	q := `"` + symbol + `"`
+
+	return map[uint16]string{
+		5:  `#,##0 ` + q + `;\-#,##0 ` + q,
+		6:  `#,##0 ` + q + `;[Red]\-#,##0 ` + q,
+		7:  `#,##0.00 ` + q + `;\-#,##0.00 ` + q,
+		8:  `#,##0.00 ` + q + `;[Red]\-#,##0.00 ` + q,
+		37: `#,##0 ;\-#,##0 `,
+		38: `#,##0 ;[Red]\-#,##0 `,
+		39: `#,##0.00 ;\-#,##0.00 `,
+		40: `#,##0.00 ;[Red]\-#,##0.00 `,
+		41: `_-* #,##0 _` + symbol + `_-;\-* #,##0 _` + symbol + `_-;_-* "-" _` + symbol + `_-;_-@_-`,
+		42: `_-* #,##0 ` + q + `_-;\-* #,##0 ` + q + `_-;_-* "-" ` + q + `_-;_-@_-`,
+		43: `_-* #,##0.00 _` + symbol + `_-;\-* #,##0.00 _` + symbol + `_-;_-* "-"?? _` + symbol + `_-;_-@_-`,
+		44: `_-* #,##0.00 ` + q + `_-;\-* #,##0.00 ` + q + `_-;_-* "-"?? ` + q + `_-;_-@_-`,
+	}
+}
@@ -0,0 +1,86 @@
+package xls
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRenderBuiltin(t *testing.T) {
+	t.Parallel()
+
+	const (
+		serial   = 43862.5625 // 2020-02-01 13:30:00, a Saturday
+		negative = -1234567.891
+	)
+
+	tests := []struct {
+		locale string
+		id     uint16
+		value  float64
+		want   string
+	}{
+		{"en-US", 0, 1234.5, "1234.5"},
+		{"en-US", 4, negative, "-1,234,567.89"},
+		{"en-US", 7, negative, "($1,234,567.89)"},
+		{"en-US", 10, 0.1234, "12.34%"},
+		{"en-US", 11, 12345, "1.23E+04"},
+		{"en-US", 12, 1.25, "1 1/4"},
+		{"en-US", 13, -0.3, "-3/10"},
+		{"en-US", 12, 0.99, "1"},
+		{"en-US", 14, serial, "2/1/2020"},
+		{"en-US", 15, serial, "1-Feb-20"},
+		{"en-US", 18, serial, "1:30 PM"},
+		{"en-US", 22, serial, "2/1/2020 13:30"},
+		{"en-US", 44, 0, "$-"},
+		{"en-US", 46, 1.5, "36:00:00"},
+		{"en-US", 48, 12345, "12.3E+3"},
+		{"de-DE", 0, 1234.5, "1234,5"},
+		{"de-DE", 4, negative, "-1.234.567,89"},
+		{"de-DE", 7, 1234.5, "1.234,50 €"},
+		{"de-DE", 12, 1234.5, "1234 1/2"},
+		{"de-DE", 13, 3.14159, "3 14/99"},
+		{"de-DE", 49, 1234.5, "1234,5"},
+		{"de-DE", 14, serial, "01.02.2020"},
+		{"de-DE", 15, serial, "01. Feb 20"},
+		{"de-DE", 22, serial, "01.02.2020 13:30"},
+		{"ru-RU", 4, 1234.5, "1 234,50"},
+		{"ru-RU", 6, -5, "-5 ₽"},
+		{"ru-RU", 17, serial, "фев.20"},
+		{"ja-JP", 14, serial, "2020/2/1"},
+		{"ja-JP", 27, serial, "R2.2.1"},
+		{"ja-JP", 28, serial, "令和2年2月1日"},
+		{"ja-JP", 33, serial, "13時30分00秒"},
+		{"ja-JP", 5, -1234, "¥-1,234"},
+		{"zh-CN", 31, serial, "2020年2月1日"},
+		{"zh-CN", 35, serial, "下午1时30分00秒"},
+	}
+
+	for _, tt := range tests {
+		wb := &WorkBook{locale: locales[tt.locale]}
+
+		got, ok := wb.renderBuiltin(tt.id, tt.value)
+		if !ok || got != tt.want {
+			t.Errorf("%s: format %d of %v = %q, %v; want %q", tt.locale, tt.id, tt.value, got, ok, tt.want)
+		}
+	}
+}
+
+func TestLocaleOption(t *testing.T) {
+	t.Parallel()
+
+	_, err := OpenContext(context.Background(), "testdata/times.xls", &Options{Locale: "xx-XX"})
+	if !errors.Is(err, ErrUnknownLocale) {
+		t.Fatalf("expected ErrUnknownLocale, got %v", err)
+	}
+
+	wb, err := OpenContext(context.Background(), "testdata/float.xls", &Options{Locale: "de-DE"})
+	if err != nil {
+		t.Fatalf("failed to open XLS file: %v", err)
+	}
+
+	// A1 is a NUMBER cell in the General format
+	if got := wb.GetSheet(0).Row(0).Col(0); got != "0,340277777777778" {
+		t.Errorf("A1 = %q, want %q", got, "0,340277777777778")
+	}
+}
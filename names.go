@@ -0,0 +1,132 @@
+//nolint:mnd
+package xls
+
+import "encoding/binary"
+
+// builtinNames are the names of the built-in defined names, indexed by the code stored in their NAME record.
+var builtinNames = []string{
+	"Consolidate_Area", "Auto_Open", "Auto_Close", "Extract", "Database", "Criteria", "Print_Area",
+	"Print_Titles", "Recorder", "Data_Form", "Auto_Activate", "Auto_Deactivate", "Sheet_Title", "_FilterDatabase",
+}
+
+// Codes of the built-in names used by the parser.
+const (
+	namePrintArea   = 0x06
+	namePrintTitles = 0x07
+)
+
+// definedName is a NAME record of the workbook globals.
+type definedName struct {
+	name    string
+	builtin bool
+	code    byte // the built-in name code, if builtin
+	hidden  bool
+	sheet   int    // zero-based index of the sheet the name is local to, or -1 for workbook names
+	formula []byte // the parsed formula (rgce)
+}
+
+// handleName decodes a NAME record.
+func handleName(wb *WorkBook, data []byte) {
+	if len(data) < 15 || wb.Is5ver {
+		return
+	}
+
+	flags := binary.LittleEndian.Uint16(data)
+	size := binary.LittleEndian.Uint16(data[4:])
+
+	n := definedName{
+		builtin: flags&0x20 != 0,
+		hidden:  flags&0x1 != 0,
+		sheet:   int(binary.LittleEndian.Uint16(data[8:])) - 1,
+	}
+
+	wb.strReader.Reset(data[14:])
+
+	var err error
+	if n.name, err = wb.getString(&wb.strReader, uint16(data[3])); err != nil {
+		return
+	}
+
+	if n.builtin && n.name != "" {
+		n.code = n.name[0]
+		if int(n.code) < len(builtinNames) {
+			n.name = builtinNames[n.code]
+		}
+	}
+
+	rest := data[len(data)-wb.strReader.Len():]
+	n.formula = append([]byte(nil), rest[:min(int(size), len(rest))]...)
+
+	wb.names = append(wb.names, n)
+}
+
+// builtinName returns the built-in name with the given code local to the given sheet, or nil.
+func (wb *WorkBook) builtinName(code byte, sheet int) *definedName {
+	for i := range wb.names {
+		if n := &wb.names[i]; n.builtin && n.code == code && n.sheet == sheet {
+			return n
+		}
+	}
+
+	return nil
+}
+
+// formulaAreas returns the cell ranges referenced by a formula made of 3D references,
+// areas and unions, such as the formulas of Print_Area and Print_Titles.
+// Decoding stops at the first token of any other kind.
+func formulaAreas(rgce []byte) []CellRange {
+	var res []CellRange
+
+	for _, area := range formulaAreas3d(rgce) {
+		res = append(res, area.CellRange)
+	}
+
+	return res
+}
+
+// area3d is a cell range of a 3D reference, with the EXTERNSHEET index of its sheet.
+type area3d struct {
+	ixti uint16
+	CellRange
+}
+
+// formulaAreas3d is formulaAreas keeping the sheets the ranges are on.
+func formulaAreas3d(rgce []byte) []area3d {
+	var res []area3d
+
+	for i := 0; i < len(rgce); {
+		switch rgce[i] {
+		case 0x10: // ptgUnion
+			i++
+		case 0x29, 0x49, 0x69: // ptgMemFunc: the size of the subexpression that follows
+			i += 3
+		case 0x3B, 0x5B, 0x7B: // ptgArea3d: ixti, rwFirst, rwLast, colFirst, colLast
+			if i+11 > len(rgce) {
+				return res
+			}
+
+			res = append(res, area3d{ixti: binary.LittleEndian.Uint16(rgce[i+1:]), CellRange: CellRange{
+				FirstRowB: binary.LittleEndian.Uint16(rgce[i+3:]),
+				LastRowB:  binary.LittleEndian.Uint16(rgce[i+5:]),
+				FristColB: binary.LittleEndian.Uint16(rgce[i+7:]) & 0x3FFF,
+				LastColB:  binary.LittleEndian.Uint16(rgce[i+9:]) & 0x3FFF,
+			}})
+			i += 11
+		case 0x3A, 0x5A, 0x7A: // ptgRef3d: ixti, rw, col
+			if i+7 > len(rgce) {
+				return res
+			}
+
+			row, col := binary.LittleEndian.Uint16(rgce[i+3:]), binary.LittleEndian.Uint16(rgce[i+5:])&0x3FFF
+			res = append(res, area3d{
+				ixti:      binary.LittleEndian.Uint16(rgce[i+1:]),
+				CellRange: CellRange{FirstRowB: row, LastRowB: row, FristColB: col, LastColB: col},
+			})
+			i += 7
+		default:
+			return res
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,641 @@
+//nolint:mnd
+package xls
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// renderBuiltin renders v with a built-in number format as it displays in the configured locale.
+// It reports false when no locale is set, the locale has no pattern for the id,
+// or the format is a date and Options.RawSerials asks for the serial number.
+func (wb *WorkBook) renderBuiltin(formatNo uint16, v float64) (string, bool) {
+	l := wb.locale
+	if l == nil {
+		return "", false
+	}
+
+	// Files written by other tools often define "General" as a custom format
+	if f := wb.Formats[formatNo]; formatNo == 0 || (f != nil && strings.EqualFold(f.str, "General")) {
+		return l.general(v), true
+	}
+
+	pattern, ok := l.formats[formatNo]
+	if !ok {
+		return "", false
+	}
+
+	if isDatePattern(pattern) {
+		if wb.opts.RawSerials {
+			return "", false
+		}
+
+		return wb.renderDatePattern(l, pattern, v), true
+	}
+
+	return l.renderNumber(pattern, v), true
+}
+
+// general renders v like formatGeneral, with the locale's decimal separator.
+func (l *locale) general(v float64) string {
+	return strings.Replace(formatGeneral(v), ".", l.decimal, 1)
+}
+
+// splitSections splits a format pattern at the semicolons separating its positive,
+// negative, zero and text sections.
+func splitSections(pattern string) []string {
+	var sections []string
+
+	start, quoted := 0, false
+
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '"':
+			quoted = !quoted
+		case '\\':
+			if !quoted {
+				i++
+			}
+		case ';':
+			if !quoted {
+				sections = append(sections, pattern[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(sections, pattern[start:])
+}
+
+// isDatePattern reports whether a pattern contains date or time tokens outside literals.
+func isDatePattern(pattern string) bool {
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '"':
+			if end := strings.IndexByte(pattern[i+1:], '"'); end >= 0 {
+				i += end + 1
+			} else {
+				return false
+			}
+		case '\\', '_', '*':
+			i++
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return false
+			}
+
+			if content := strings.ToLower(pattern[i+1 : i+1+end]); content != "" && strings.Trim(content, "hms") == "" {
+				return true
+			}
+
+			i += end + 1
+		case 'E', 'e':
+			// E+ and E- are the exponent of a scientific format
+			if i+1 < len(pattern) && (pattern[i+1] == '+' || pattern[i+1] == '-') {
+				i++
+
+				continue
+			}
+
+			return true
+		case 'y', 'Y', 'm', 'M', 'd', 'D', 'h', 'H', 's', 'S', 'g', 'G':
+			return true
+		}
+	}
+
+	return false
+}
+
+// numberSpec describes the placeholders of one section of a number format.
+type numberSpec struct {
+	intDigits   int // '0' placeholders before the decimal point
+	intPlaces   int // all placeholders before the decimal point
+	decimals    int // placeholders after the decimal point
+	minDecimals int // '0' placeholders after the decimal point
+	group       bool
+	scale       int // trailing thousands separators, each dividing by 1000
+	percent     int
+	exponent    bool
+	expDigits   int
+	expPlus     bool
+	denominator int // placeholders after the slash of a fraction
+}
+
+// isExponent reports whether the rune at i starts the E+/E- of a scientific format.
+func isExponent(runes []rune, i int) bool {
+	return (runes[i] == 'E' || runes[i] == 'e') && i+1 < len(runes) && (runes[i+1] == '+' || runes[i+1] == '-')
+}
+
+// skipLiteral returns the index of the last rune of a literal starting at i:
+// a quoted string, an escaped character, a padding or fill character, or a bracketed section.
+// It returns -1 if no literal starts at i.
+func skipLiteral(runes []rune, i int) int {
+	closing := func(r rune) int {
+		for j := i + 1; j < len(runes); j++ {
+			if runes[j] == r {
+				return j
+			}
+		}
+
+		return len(runes) - 1
+	}
+
+	switch runes[i] {
+	case '"':
+		return closing('"')
+	case '[':
+		return closing(']')
+	case '\\', '_', '*':
+		return min(i+1, len(runes)-1)
+	}
+
+	return -1
+}
+
+func parseNumberSpec(runes []rune) numberSpec {
+	var spec numberSpec
+
+	inDecimals, inExponent, inFraction, seen, commas := false, false, false, false, 0
+
+	for i := 0; i < len(runes); i++ {
+		if end := skipLiteral(runes, i); end >= 0 {
+			i = end
+
+			continue
+		}
+
+		switch c := runes[i]; {
+		case c == '0' || c == '#' || c == '?':
+			switch {
+			case inFraction:
+				spec.denominator++
+			case inExponent:
+				spec.expDigits++
+			case inDecimals:
+				spec.decimals++
+				if c == '0' {
+					spec.minDecimals++
+				}
+			default:
+				if commas > 0 {
+					spec.group = true
+					commas = 0
+				}
+
+				spec.intPlaces++
+				if c == '0' {
+					spec.intDigits++
+				}
+			}
+
+			seen = true
+		case c == ',' && seen && !inDecimals && !inExponent:
+			commas++
+		case c == '.' && !inExponent:
+			inDecimals = true
+			spec.scale += commas
+			commas = 0
+		case c == '/' && !inExponent:
+			inFraction = true
+		case c == '%':
+			spec.percent++
+		case isExponent(runes, i):
+			spec.exponent = true
+			spec.expPlus = runes[i+1] == '+'
+			spec.scale += commas
+			commas = 0
+			inExponent = true
+			i++
+		}
+	}
+
+	spec.scale += commas
+
+	return spec
+}
+
+// renderNumber renders v with a number format pattern.
+func (l *locale) renderNumber(pattern string, v float64) string {
+	sections := splitSections(pattern)
+	section, sign := sections[0], ""
+
+	switch {
+	case v < 0 && len(sections) > 1:
+		section, v = sections[1], -v
+	case v == 0 && len(sections) > 2:
+		section = sections[2]
+	case v < 0:
+		sign, v = "-", -v
+	}
+
+	runes := []rune(section)
+	spec := parseNumberSpec(runes)
+
+	if spec.denominator > 0 {
+		return sign + formatFraction(spec, v)
+	}
+
+	var sb strings.Builder
+
+	written := false
+
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+
+		switch {
+		case c == '"':
+			end := skipLiteral(runes, i)
+			sb.WriteString(strings.TrimSuffix(string(runes[i+1:end+1]), `"`))
+			i = end
+		case c == '\\':
+			if i+1 < len(runes) {
+				sb.WriteRune(runes[i+1])
+			}
+
+			i++
+		case c == '_':
+			sb.WriteByte(' ')
+			i++
+		case c == '*':
+			i++
+		case c == '[':
+			end := skipLiteral(runes, i)
+			// [$€-407] is a currency symbol with a locale id, anything else a color or condition
+			if content := string(runes[i+1 : end]); strings.HasPrefix(content, "$") {
+				symbol, _, _ := strings.Cut(content[1:], "-")
+				sb.WriteString(symbol)
+			}
+
+			i = end
+		case c == '0' || c == '#' || c == '?' || c == '.' || (c == ',' && written) || isExponent(runes, i):
+			if !written {
+				sb.WriteString(l.formatNumber(spec, v))
+				written = true
+			}
+
+			if isExponent(runes, i) {
+				i++
+			}
+		case c == '@':
+			sb.WriteString(l.general(v))
+		default:
+			sb.WriteRune(c)
+		}
+	}
+
+	// Padding only aligns the number inside the cell
+	return sign + strings.TrimSpace(sb.String())
+}
+
+// formatNumber renders the digits of a non-negative v as described by spec.
+func (l *locale) formatNumber(spec numberSpec, v float64) string {
+	v *= math.Pow(100, float64(spec.percent)) / math.Pow(1000, float64(spec.scale))
+
+	if !spec.exponent {
+		return l.formatFixed(spec, v)
+	}
+
+	exp := 0
+	if v != 0 {
+		exp = int(math.Floor(math.Log10(v)))
+	}
+
+	// ##0.0E+0 is engineering notation: the exponent is a multiple of the integer places
+	engineering := spec.intPlaces > 1 && spec.intDigits < spec.intPlaces
+	if engineering {
+		exp = int(math.Floor(float64(exp)/float64(spec.intPlaces))) * spec.intPlaces
+	}
+
+	mantissa := v / math.Pow10(exp)
+	if rounded, _ := strconv.ParseFloat(strconv.FormatFloat(mantissa, 'f', spec.decimals, 64), 64); !engineering && rounded >= 10 {
+		exp++
+		mantissa = v / math.Pow10(exp)
+	}
+
+	spec.group = false
+	res := l.formatFixed(spec, mantissa) + "E"
+
+	if exp < 0 {
+		res += "-"
+	} else if spec.expPlus {
+		res += "+"
+	}
+
+	return res + fmt.Sprintf("%0*d", spec.expDigits, abs(exp))
+}
+
+// formatFraction renders a non-negative v as a whole number and the closest fraction whose
+// denominator has at most as many digits as spec has placeholders after the slash.
+func formatFraction(spec numberSpec, v float64) string {
+	whole := math.Floor(v)
+	frac := v - whole
+
+	num, den := 0.0, 1.0
+	for d := 1.0; d < math.Pow10(spec.denominator); d++ {
+		if n := math.Round(frac * d); math.Abs(frac-n/d) < math.Abs(frac-num/den) {
+			num, den = n, d
+		}
+	}
+
+	if num == den {
+		whole, num = whole+1, 0
+	}
+
+	res := strconv.FormatFloat(whole, 'f', 0, 64)
+
+	switch {
+	case num == 0:
+		return res
+	case whole == 0:
+		res = ""
+	default:
+		res += " "
+	}
+
+	return res + strconv.FormatFloat(num, 'f', 0, 64) + "/" + strconv.FormatFloat(den, 'f', 0, 64)
+}
+
+// formatFixed renders a non-negative v with the integer and decimal placeholders of spec.
+func (l *locale) formatFixed(spec numberSpec, v float64) string {
+	integer, fraction, _ := strings.Cut(strconv.FormatFloat(v, 'f', spec.decimals, 64), ".")
+
+	for len(fraction) > spec.minDecimals && strings.HasSuffix(fraction, "0") {
+		fraction = fraction[:len(fraction)-1]
+	}
+
+	if integer == "0" && spec.intDigits == 0 {
+		integer = ""
+	}
+
+	if pad := spec.intDigits - len(integer); pad > 0 {
+		integer = strings.Repeat("0", pad) + integer
+	}
+
+	if spec.group {
+		integer = groupDigits(integer, l.group)
+	}
+
+	if fraction == "" {
+		return integer
+	}
+
+	return integer + l.decimal + fraction
+}
+
+// groupDigits inserts sep between every three digits, counting from the right.
+func groupDigits(digits, sep string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+
+	var sb strings.Builder
+
+	head := len(digits) % 3
+	if head > 0 {
+		sb.WriteString(digits[:head])
+	}
+
+	for i := head; i < len(digits); i += 3 {
+		if sb.Len() > 0 {
+			sb.WriteString(sep)
+		}
+
+		sb.WriteString(digits[i : i+3])
+	}
+
+	return sb.String()
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+// japaneseEras lists the eras of the Japanese calendar, newest first, with the names
+// displayed for one, two and three "g" tokens.
+var japaneseEras = []struct {
+	start int // yyyymmdd of the first day
+	names [3]string
+}{
+	{20190501, [3]string{"R", "令", "令和"}},
+	{19890108, [3]string{"H", "平", "平成"}},
+	{19261225, [3]string{"S", "昭", "昭和"}},
+	{19120730, [3]string{"T", "大", "大正"}},
+	{18680908, [3]string{"M", "明", "明治"}},
+}
+
+// japaneseEra returns the era names and the year within the era of t.
+func japaneseEra(t time.Time) ([3]string, int) {
+	day := t.Year()*10000 + int(t.Month())*100 + t.Day()
+
+	for _, era := range japaneseEras {
+		if day >= era.start {
+			return era.names, t.Year() - era.start/10000 + 1
+		}
+	}
+
+	return [3]string{}, t.Year()
+}
+
+// renderDatePattern renders the serial v with a date or time pattern.
+func (wb *WorkBook) renderDatePattern(l *locale, pattern string, v float64) string {
+	section := splitSections(pattern)[0]
+	lower := strings.ToLower(section)
+	hour12 := strings.Contains(lower, "am/pm") || strings.Contains(lower, "a/p") || strings.Contains(section, "上午/下午")
+
+	// Round to the precision displayed, so 0.99999 shows as the next second rather than 59
+	precision := time.Second
+	if i := strings.Index(lower, "s."); i >= 0 {
+		digits := len(lower[i+2:]) - len(strings.TrimLeft(lower[i+2:], "0"))
+		precision = time.Duration(math.Pow10(9 - min(digits, 9)))
+	}
+
+	t := wb.ConvertSerial(v).Round(precision)
+	seconds := math.Round(v * 86400)
+
+	runes := []rune(section)
+
+	var sb strings.Builder
+
+	afterHour := false
+
+	for i := 0; i < len(runes); i++ {
+		c := unicode.ToLower(runes[i])
+
+		n := 1
+		for i+n < len(runes) && unicode.ToLower(runes[i+n]) == c {
+			n++
+		}
+
+		switch {
+		case c == '"':
+			end := skipLiteral(runes, i)
+			sb.WriteString(strings.TrimSuffix(string(runes[i+1:end+1]), `"`))
+			i = end
+		case c == '\\':
+			if i+1 < len(runes) {
+				sb.WriteRune(runes[i+1])
+			}
+
+			i++
+		case c == '_':
+			sb.WriteByte(' ')
+			i++
+		case c == '*':
+			i++
+		case c == '[':
+			end := skipLiteral(runes, i)
+			content := strings.ToLower(string(runes[i+1 : end]))
+
+			// [h], [mm] and [ss] show the elapsed time instead of the time of day
+			if content != "" && strings.Trim(content, "hms") == "" {
+				unit := map[byte]float64{'h': 3600, 'm': 60, 's': 1}[content[0]]
+				fmt.Fprintf(&sb, "%0*d", len(content), int64(seconds/unit))
+				afterHour = content[0] == 'h'
+			}
+
+			i = end
+		case c == 'y':
+			if n <= 2 {
+				fmt.Fprintf(&sb, "%02d", t.Year()%100)
+			} else {
+				fmt.Fprintf(&sb, "%04d", t.Year())
+			}
+
+			i += n - 1
+		case c == 'e':
+			year := t.Year()
+			if l.japanese {
+				_, year = japaneseEra(t)
+			}
+
+			fmt.Fprintf(&sb, "%0*d", min(n, 2), year)
+			i += n - 1
+		case c == 'g':
+			if l.japanese {
+				names, _ := japaneseEra(t)
+				sb.WriteString(names[min(n, 3)-1])
+			}
+
+			i += n - 1
+		case c == 'm' && n <= 2 && (afterHour || nextLetter(runes, i+n) == 's'):
+			fmt.Fprintf(&sb, "%0*d", n, t.Minute())
+			afterHour = false
+			i += n - 1
+		case c == 'm':
+			switch n {
+			case 1, 2:
+				fmt.Fprintf(&sb, "%0*d", n, int(t.Month()))
+			case 3:
+				sb.WriteString(l.monthsAb[t.Month()-1])
+			case 4:
+				sb.WriteString(l.months[t.Month()-1])
+			default:
+				sb.WriteString(string([]rune(l.months[t.Month()-1])[:1]))
+			}
+
+			afterHour = false
+			i += n - 1
+		case c == 'd':
+			switch n {
+			case 1, 2:
+				fmt.Fprintf(&sb, "%0*d", n, t.Day())
+			case 3:
+				sb.WriteString(l.daysAb[t.Weekday()])
+			default:
+				sb.WriteString(l.days[t.Weekday()])
+			}
+
+			afterHour = false
+			i += n - 1
+		case c == 'h':
+			hour := t.Hour()
+			if hour12 {
+				hour %= 12
+				if hour == 0 {
+					hour = 12
+				}
+			}
+
+			fmt.Fprintf(&sb, "%0*d", min(n, 2), hour)
+			afterHour = true
+			i += n - 1
+		case c == 's':
+			fmt.Fprintf(&sb, "%0*d", min(n, 2), t.Second())
+			i += n - 1
+
+			// ss.000 shows fractions of a second
+			if i+2 < len(runes) && runes[i+1] == '.' && runes[i+2] == '0' {
+				digits := 0
+				for i+2+digits < len(runes) && runes[i+2+digits] == '0' {
+					digits++
+				}
+
+				fraction := fmt.Sprintf("%09d", t.Nanosecond())[:min(digits, 9)]
+				sb.WriteString(l.decimal + fraction)
+				i += 1 + digits
+			}
+
+			afterHour = false
+		case c == 'a' && hasPrefixFold(runes[i:], "am/pm"):
+			sb.WriteString(l.ampm(t, false))
+			i += 4
+		case c == 'a' && hasPrefixFold(runes[i:], "a/p"):
+			sb.WriteString(l.ampm(t, true))
+			i += 2
+		case strings.HasPrefix(string(runes[i:]), "上午/下午"):
+			sb.WriteString(l.ampm(t, false))
+			i += 4
+		default:
+			sb.WriteRune(runes[i])
+		}
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+// ampm returns the locale's morning or afternoon designator for t, or only its first letter.
+func (l *locale) ampm(t time.Time, short bool) string {
+	designator := l.am
+	if t.Hour() >= 12 {
+		designator = l.pm
+	}
+
+	if short {
+		return string([]rune(designator)[:1])
+	}
+
+	return designator
+}
+
+// nextLetter returns the next letter from index i on, lower-cased, or 0 if there is none.
+func nextLetter(runes []rune, i int) rune {
+	for ; i < len(runes); i++ {
+		if end := skipLiteral(runes, i); end >= 0 {
+			i = end
+
+			continue
+		}
+
+		if unicode.IsLetter(runes[i]) {
+			return unicode.ToLower(runes[i])
+		}
+	}
+
+	return 0
+}
+
+// hasPrefixFold reports whether runes start with prefix, ignoring case.
+func hasPrefixFold(runes []rune, prefix string) bool {
+	p := []rune(prefix)
+
+	return len(runes) >= len(p) && strings.EqualFold(string(runes[:len(p)]), prefix)
+}
@@ -0,0 +1,334 @@
+//nolint:mnd
+package xls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/vstasn/ole2"
+)
+
+// EmbeddedObject is an OLE object embedded in a sheet, such as a Word document, a PDF or another workbook.
+type EmbeddedObject struct {
+	// ID is the object identifier of the OBJ record.
+	ID int
+	// ProgID names the server application, e.g. "Word.Document.12", "AcroExch.Document.DC" or "Package".
+	ProgID string
+	// From and To locate the top-left and bottom-right corners of the object's icon or preview.
+	From, To CellAnchor
+	// Storage is the name of the OLE storage that holds the object, "MBD" and eight hex digits.
+	Storage string
+	// FileName is the original name of a file wrapped by the Object Packager, if known.
+	FileName string
+	// Data is the embedded file: the file wrapped by the Object Packager, the "Package" stream of
+	// Office 2007 documents or the "CONTENTS" stream of PDFs and the like. It is nil for objects
+	// kept as OLE2 documents, such as Word 97 files, whose streams are only in Streams.
+	Data []byte
+	// Streams holds the streams of the object's storage. Streams of nested storages are
+	// named by their path, joined with "/".
+	Streams map[string][]byte
+}
+
+// oleObject is the part of an OBJ record that links to an embedded object.
+type oleObject struct {
+	index   int // position among the sheet's OBJ records, which is that of its shape
+	id      int
+	progID  string
+	storage uint32
+}
+
+// Objects returns the OLE objects embedded in the sheet, in drawing order, with the data read
+// from their storages. Controls, DDE links and camera pictures are left out. It fails if the
+// OLE2 container cannot be read.
+func (w *WorkSheet) Objects() ([]EmbeddedObject, error) {
+	if len(w.objects) == 0 {
+		return nil, nil
+	}
+
+	var shapes []escherShape
+
+	for _, shape := range escherShapes(w.drawing) {
+		if shape.clientData {
+			shapes = append(shapes, shape)
+		}
+	}
+
+	var storages map[string]*ole2.File
+	if w.wb.ole != nil {
+		storages = make(map[string]*ole2.File)
+		for _, file := range w.wb.oleChildren(w.wb.oleRoot) {
+			if file.Type == ole2.USERSTORAGE {
+				storages[file.Name()] = file
+			}
+		}
+	}
+
+	objects := make([]EmbeddedObject, 0, len(w.objects))
+
+	for _, obj := range w.objects {
+		o := EmbeddedObject{ID: obj.id, ProgID: obj.progID, Storage: fmt.Sprintf("MBD%08X", obj.storage)}
+		if obj.index < len(shapes) {
+			o.From, o.To = shapes[obj.index].cells()
+		}
+
+		if storage := storages[o.Storage]; storage != nil {
+			o.Streams = make(map[string][]byte)
+			if err := w.wb.readStorage(storage, "", o.Streams, 0); err != nil {
+				return nil, fmt.Errorf("xls: object %s of sheet %q: %w", o.Storage, w.Name, err)
+			}
+
+			if o.ProgID == "" {
+				o.ProgID = compObjProgID(o.Streams["\x01CompObj"])
+			}
+
+			o.FileName, o.Data = objectPayload(o.Streams)
+		}
+
+		objects = append(objects, o)
+	}
+
+	return objects, nil
+}
+
+// addObject decodes an OBJ record: subrecords of a type and a size, up to ftEnd. Embedded
+// objects are pictures with an ftPictFmla subrecord that links them to their storage.
+func (w *WorkSheet) addObject(data []byte) {
+	index := w.objCount
+	w.objCount++
+
+	var (
+		ot, id  uint16
+		flags   uint16
+		pictFml []byte
+	)
+
+	for off := 0; off+4 <= len(data); {
+		ft, cb := binary.LittleEndian.Uint16(data[off:]), int(binary.LittleEndian.Uint16(data[off+2:]))
+		// ftLbsData of list boxes gives a size that does not cover its data
+		if ft == 0x00 || ft == 0x13 {
+			break
+		}
+
+		body := data[off+4 : min(off+4+cb, len(data))]
+
+		switch ft {
+		case 0x15: // ftCmo: object type and identifier
+			if len(body) >= 4 {
+				ot, id = binary.LittleEndian.Uint16(body), binary.LittleEndian.Uint16(body[2:])
+			}
+		case 0x08: // ftPioGrbit
+			if len(body) >= 2 {
+				flags = binary.LittleEndian.Uint16(body)
+			}
+		case 0x09: // ftPictFmla
+			pictFml = body
+		}
+
+		off += 4 + cb
+	}
+
+	// fDde, fCtl, fPrstm and fCamera: not embedded in a storage of its own
+	if ot != 0x08 || pictFml == nil || flags&(0x02|0x10|0x20|0x80) != 0 {
+		return
+	}
+
+	obj, ok := w.wb.decodePictFmla(pictFml)
+	if !ok {
+		return
+	}
+
+	obj.index, obj.id = index, int(id)
+	w.objects = append(w.objects, obj)
+}
+
+// decodePictFmla decodes an ftPictFmla subrecord: the size of the formula, the formula with its
+// size, four unused bytes and the tokens, then for embedded objects the class name, and after
+// the formula the identifier of the storage.
+func (wb *WorkBook) decodePictFmla(data []byte) (oleObject, bool) {
+	if len(data) < 2 {
+		return oleObject{}, false
+	}
+
+	cbFmla := int(binary.LittleEndian.Uint16(data))
+	if cbFmla < 6 || len(data) < 2+cbFmla+4 {
+		return oleObject{}, false
+	}
+
+	obj := oleObject{storage: binary.LittleEndian.Uint32(data[2+cbFmla:])}
+
+	cce := int(binary.LittleEndian.Uint16(data[2:]) & 0x7FFF)
+	info := 8 + cce
+
+	// a ptgTbl token is followed by the class name: a marker, its length and a reserved byte
+	if cce > 0 && data[8] == 0x02 && info+3 <= 2+cbFmla && data[info] == 0x03 {
+		wb.strReader.Reset(data[info+3 : 2+cbFmla])
+		obj.progID, _ = wb.getString(&wb.strReader, uint16(data[info+1]))
+	}
+
+	return obj, true
+}
+
+// oleChildren returns the entries of an OLE2 storage, walking the tree of its directory entries.
+func (wb *WorkBook) oleChildren(storage *ole2.File) []*ole2.File {
+	if storage == nil {
+		return nil
+	}
+
+	var (
+		res  []*ole2.File
+		seen = make(map[uint32]bool)
+		walk func(i uint32)
+	)
+
+	walk = func(i uint32) {
+		if int64(i) >= int64(len(wb.oleDir)) || seen[i] {
+			return
+		}
+
+		seen[i] = true
+		file := wb.oleDir[i]
+
+		walk(file.Left)
+		res = append(res, file)
+		walk(file.Right)
+	}
+
+	walk(storage.Child)
+
+	return res
+}
+
+// readStorage reads the streams of an OLE2 storage and of the storages nested in it into streams.
+func (wb *WorkBook) readStorage(storage *ole2.File, prefix string, streams map[string][]byte, depth int) error {
+	if depth > 8 {
+		return nil
+	}
+
+	for _, file := range wb.oleChildren(storage) {
+		switch file.Type {
+		case ole2.USERSTREAM:
+			data, err := io.ReadAll(io.LimitReader(wb.ole.OpenFile(file, wb.oleRoot), int64(file.Size)))
+			if err != nil {
+				return err
+			}
+
+			streams[prefix+file.Name()] = data
+		case ole2.USERSTORAGE:
+			if err := wb.readStorage(file, prefix+file.Name()+"/", streams, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// objectPayload returns the embedded file of an object from its storage's streams.
+func objectPayload(streams map[string][]byte) (fileName string, data []byte) {
+	if native, ok := streams["\x01Ole10Native"]; ok {
+		if fileName, data, ok = decodeOle10Native(native); ok {
+			return fileName, data
+		}
+
+		return "", native
+	}
+
+	for _, name := range []string{"Package", "CONTENTS", "Contents"} {
+		if data, ok := streams[name]; ok {
+			return "", data
+		}
+	}
+
+	return "", nil
+}
+
+// decodeOle10Native decodes the Ole10Native stream of the Object Packager: the stream size, a
+// flag, the label, the source path, two unknown values, the temporary path, and the size and
+// contents of the file. Streams that only hold the size and the data are returned as they are.
+func decodeOle10Native(data []byte) (fileName string, file []byte, ok bool) {
+	if len(data) < 6 {
+		return "", nil, false
+	}
+
+	r := bytes.NewReader(data[6:])
+
+	cString := func() string {
+		var b []byte
+		for {
+			c, err := r.ReadByte()
+			if err != nil || c == 0 {
+				return string(b)
+			}
+
+			b = append(b, c)
+		}
+	}
+
+	label := cString()
+	cString() // source path
+
+	var skip [8]byte
+	if _, err := io.ReadFull(r, skip[:]); err != nil {
+		return "", data[4:], true
+	}
+
+	cString() // temporary path
+
+	var size uint32
+	if binary.Read(r, binary.LittleEndian, &size) != nil || int64(size) > int64(r.Len()) {
+		return "", data[4:], true
+	}
+
+	file = make([]byte, size)
+	_, _ = io.ReadFull(r, file)
+
+	return label, file, true
+}
+
+// compObjProgID returns the ProgID of a CompObj stream: a 28-byte header, the display name,
+// the clipboard format, given as a number or a name, and then the ProgID.
+func compObjProgID(data []byte) string {
+	r := bytes.NewReader(data)
+	if _, err := r.Seek(28, io.SeekStart); err != nil {
+		return ""
+	}
+
+	lengthPrefixed := func() (string, bool) {
+		var n uint32
+		if binary.Read(r, binary.LittleEndian, &n) != nil || int64(n) > int64(r.Len()) {
+			return "", false
+		}
+
+		b := make([]byte, n)
+		_, _ = io.ReadFull(r, b)
+
+		return string(bytes.TrimRight(b, "\x00")), true
+	}
+
+	if _, ok := lengthPrefixed(); !ok {
+		return ""
+	}
+
+	var marker uint32
+	if binary.Read(r, binary.LittleEndian, &marker) != nil {
+		return ""
+	}
+
+	switch marker {
+	case 0:
+	case 0xFFFFFFFE, 0xFFFFFFFF:
+		if _, err := r.Seek(4, io.SeekCurrent); err != nil {
+			return ""
+		}
+	default:
+		if _, err := r.Seek(int64(marker), io.SeekCurrent); err != nil {
+			return ""
+		}
+	}
+
+	progID, _ := lengthPrefixed()
+
+	return progID
+}
@@ -0,0 +1,279 @@
+package xls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/vstasn/ole2"
+)
+
+// TestObjects decodes the OBJ records of an embedded workbook, a control and a chart, and
+// anchors the embedded workbook with the shape of its OBJ record.
+func TestObjects(t *testing.T) {
+	t.Parallel()
+
+	sub := func(ft uint16, body ...[]byte) []byte {
+		data := bytes.Join(body, nil)
+		res := binary.LittleEndian.AppendUint16(nil, ft)
+		res = binary.LittleEndian.AppendUint16(res, uint16(len(data)))
+
+		return append(res, data...)
+	}
+
+	cmo := func(ot, id uint16) []byte {
+		data := binary.LittleEndian.AppendUint16(nil, ot)
+		data = binary.LittleEndian.AppendUint16(data, id)
+
+		return sub(0x15, data, make([]byte, 14))
+	}
+
+	// ptgTbl, then the class name as an 8-bit string
+	progID := "Excel.Sheet.8"
+	fmla := []byte{5, 0, 0, 0, 0, 0, 0x02, 0, 0, 0, 0, 3, byte(len(progID)), 0, 0}
+	fmla = append(fmla, progID...)
+	fmla = append(binary.LittleEndian.AppendUint16(nil, uint16(len(fmla))), fmla...)
+	fmla = binary.LittleEndian.AppendUint32(fmla, 0x2A)
+
+	embedded := biffRecord(0x5D, cmo(8, 3), sub(0x08, []byte{0x01, 0}), sub(0x09, fmla), sub(0))
+	control := biffRecord(0x5D, cmo(8, 4), sub(0x08, []byte{0x11, 0}), sub(0x09, fmla), sub(0))
+	chart := biffRecord(0x5D, cmo(5, 5), sub(0))
+
+	anchor := make([]byte, 2)
+	for _, v := range []uint16{1, 0, 4, 0, 3, 0, 9, 0} {
+		anchor = binary.LittleEndian.AppendUint16(anchor, v)
+	}
+
+	shape := func(withAnchor bool) []byte {
+		var a []byte
+		if withAnchor {
+			a = escherRecord(0, 0xF010, anchor)
+		}
+
+		return escherRecord(0xF, 0xF004, escherRecord(0xC92, 0xF00A, make([]byte, 8)), a, escherRecord(0, 0xF011))
+	}
+
+	drawing := escherRecord(0xF, 0xF002,
+		escherRecord(0xF, 0xF003,
+			escherRecord(0xF, 0xF004, escherRecord(0, 0xF009, make([]byte, 16))),
+			shape(false), shape(false), shape(true)))
+
+	sheet := &WorkSheet{wb: &WorkBook{}, Name: "Costs"}
+	sheet.parseBof(bof{ID: 0xEC}, drawing, nil)
+
+	for _, record := range [][]byte{chart, control, embedded} {
+		sheet.parseBof(bof{ID: binary.LittleEndian.Uint16(record)}, record[4:], nil)
+	}
+
+	got, err := sheet.Objects()
+	if err != nil {
+		t.Fatalf("Objects() failed: %v", err)
+	}
+
+	want := []EmbeddedObject{{
+		ID:      3,
+		ProgID:  "Excel.Sheet.8",
+		From:    CellAnchor{Row: 4, Col: 1},
+		To:      CellAnchor{Row: 9, Col: 3},
+		Storage: "MBD0000002A",
+	}}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Objects() = %+v, want %+v", got, want)
+	}
+}
+
+func TestObjectPayload(t *testing.T) {
+	t.Parallel()
+
+	file := []byte("%PDF-1.4 contract")
+
+	native := []byte{0, 0, 0, 0, 2, 0}
+	native = append(native, "contract.pdf\x00C:\\contract.pdf\x00"...)
+	native = append(native, 0, 0, 3, 0, 0, 0, 0, 0)
+	native = append(native, "C:\\Temp\\contract.pdf\x00"...)
+	native = binary.LittleEndian.AppendUint32(native, uint32(len(file)))
+	native = append(native, file...)
+	binary.LittleEndian.PutUint32(native, uint32(len(native)-4))
+
+	name, data := objectPayload(map[string][]byte{"\x01Ole10Native": native})
+	if name != "contract.pdf" || !bytes.Equal(data, file) {
+		t.Errorf("Ole10Native: got %q, %q", name, data)
+	}
+
+	if _, data := objectPayload(map[string][]byte{"CONTENTS": file}); !bytes.Equal(data, file) {
+		t.Errorf("CONTENTS: got %q", data)
+	}
+
+	compObj := make([]byte, 28)
+	for _, s := range []string{"Microsoft Word Document\x00", "\xff\xff\xff\xff\x08\x00\x00\x00", "Word.Document.8\x00"} {
+		if s[0] != 0xFF {
+			compObj = binary.LittleEndian.AppendUint32(compObj, uint32(len(s)))
+		}
+
+		compObj = append(compObj, s...)
+	}
+
+	if got := compObjProgID(compObj); got != "Word.Document.8" {
+		t.Errorf("compObjProgID() = %q, want Word.Document.8", got)
+	}
+}
+
+// oleStream is a stream of a compound file built by oleFile.
+type oleStream struct {
+	name string
+	data []byte
+}
+
+// oleFile builds an OLE2 compound file of 512-byte sectors: the FAT, the directory, then the
+// streams, which are at least 4096 bytes so none goes to the mini stream. The root entry holds
+// a single storage with the streams.
+func oleFile(t *testing.T, storage string, streams []oleStream) *WorkBook {
+	t.Helper()
+
+	const (
+		freeSect   = 0xFFFFFFFF
+		endOfChain = 0xFFFFFFFE
+		fatSect    = 0xFFFFFFFD
+		noStream   = 0xFFFFFFFF
+	)
+
+	entry := func(name string, typ byte) ole2.File {
+		f := ole2.File{Type: typ, Left: noStream, Right: noStream, Child: noStream, Sstart: endOfChain}
+		n := utf16.Encode([]rune(name))
+		copy(f.NameBts[:], n)
+		f.Bsize = uint16(2 * (len(n) + 1))
+
+		return f
+	}
+
+	root, dir := entry("Root Entry", ole2.ROOT), entry(storage, ole2.USERSTORAGE)
+	root.Child = 1
+
+	entries := []ole2.File{root, dir}
+	fat := []uint32{fatSect, endOfChain}
+
+	var data []byte
+
+	for i, s := range streams {
+		if len(s.data) < 4096 {
+			t.Fatalf("stream %q is shorter than 4096 bytes", s.name)
+		}
+
+		f := entry(s.name, ole2.USERSTREAM)
+		f.Sstart, f.Size = uint32(len(fat)), uint32(len(s.data))
+
+		if i == 0 {
+			entries[1].Child = 2
+		} else {
+			entries[len(entries)-1].Right = uint32(len(entries))
+		}
+
+		entries = append(entries, f)
+
+		sectors := (len(s.data) + 511) / 512
+		for j := 1; j < sectors; j++ {
+			fat = append(fat, uint32(len(fat)+1))
+		}
+
+		fat = append(fat, endOfChain)
+		data = append(data, s.data...)
+		data = append(data, make([]byte, sectors*512-len(s.data))...)
+	}
+
+	if len(entries) > 4 || len(fat) > 128 {
+		t.Fatalf("%d entries and %d sectors do not fit in one directory and FAT sector", len(entries), len(fat))
+	}
+
+	header := ole2.Header{
+		Id:           [2]uint32{0xE011CFD0, 0xE11AB1A1},
+		Verminor:     0x3E,
+		Verdll:       3,
+		Byteorder:    0xFFFE,
+		Lsectorb:     9,
+		Lssectorb:    6,
+		Cfat:         1,
+		Dirstart:     1,
+		Sectorcutoff: 4096,
+		Sfatstart:    endOfChain,
+		Difstart:     endOfChain,
+	}
+
+	for i := range header.Msat {
+		header.Msat[i] = freeSect
+	}
+
+	header.Msat[0] = 0
+
+	for len(fat) < 128 {
+		fat = append(fat, freeSect)
+	}
+
+	var buf bytes.Buffer
+
+	for _, v := range []any{header, fat, entries, make([]byte, 128*(4-len(entries))), data} {
+		if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
+			t.Fatalf("failed to write the compound file: %v", err)
+		}
+	}
+
+	ole, err := ole2.Open(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("failed to open the compound file: %v", err)
+	}
+
+	files, err := ole.ListDir()
+	if err != nil {
+		t.Fatalf("failed to list the compound file: %v", err)
+	}
+
+	return &WorkBook{ole: ole, oleDir: files, oleRoot: files[0]}
+}
+
+// TestObjectStorage reads an embedded object from its MBD storage: the ProgID from the CompObj
+// stream and the file wrapped in the Ole10Native stream.
+func TestObjectStorage(t *testing.T) {
+	t.Parallel()
+
+	file := bytes.Repeat([]byte("%PDF-1.4 contract "), 300)
+
+	native := []byte{0, 0, 0, 0, 2, 0}
+	native = append(native, "contract.pdf\x00C:\\contract.pdf\x00"...)
+	native = append(native, 0, 0, 3, 0, 0, 0, 0, 0)
+	native = append(native, "C:\\Temp\\contract.pdf\x00"...)
+	native = binary.LittleEndian.AppendUint32(native, uint32(len(file)))
+	native = append(native, file...)
+	binary.LittleEndian.PutUint32(native, uint32(len(native)-4))
+
+	// the display name, no clipboard format, the ProgID, and padding up to the size of a regular stream
+	compObj := binary.LittleEndian.AppendUint32(make([]byte, 28), 8)
+	compObj = append(compObj, "Package\x00"...)
+	compObj = binary.LittleEndian.AppendUint32(compObj, 0)
+	compObj = binary.LittleEndian.AppendUint32(compObj, 8)
+	compObj = append(compObj, "Package\x00"...)
+	compObj = append(compObj, make([]byte, 4096)...)
+
+	wb := oleFile(t, "MBD0000002A", []oleStream{{"\x01CompObj", compObj}, {"\x01Ole10Native", native}})
+	sheet := &WorkSheet{wb: wb, Name: "Contracts", objects: []oleObject{{id: 3, storage: 0x2A}}}
+
+	got, err := sheet.Objects()
+	if err != nil {
+		t.Fatalf("Objects() failed: %v", err)
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("Objects() = %+v, want one object", got)
+	}
+
+	o := got[0]
+	if o.ID != 3 || o.Storage != "MBD0000002A" || o.ProgID != "Package" || o.FileName != "contract.pdf" || !bytes.Equal(o.Data, file) {
+		t.Errorf("Objects() = {ID: %d, Storage: %q, ProgID: %q, FileName: %q} with %d bytes of data",
+			o.ID, o.Storage, o.ProgID, o.FileName, len(o.Data))
+	}
+
+	if !bytes.Equal(o.Streams["\x01CompObj"], compObj) || !bytes.Equal(o.Streams["\x01Ole10Native"], native) {
+		t.Errorf("Streams = %d streams, want the CompObj and Ole10Native streams", len(o.Streams))
+	}
+}
@@ -0,0 +1,144 @@
+package xls
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultDateLayout is the layout used for date cells when Options does not name one.
+const DefaultDateLayout = "02.01.2006"
+
+// progressInterval is the number of records parsed between two progress callbacks.
+const progressInterval = 1024
+
+// Options tunes how a workbook is opened and parsed.
+// A nil *Options and the zero value both mean the defaults.
+type Options struct {
+	// Progress, if set, is called periodically while records are parsed,
+	// and once more when the workbook globals or a sheet have been parsed completely.
+	Progress ProgressFunc
+
+	// LazySST defers decoding of the shared string table until a cell needs a string.
+	// Strings are located through the EXTSST record, so opening stays cheap and memory stays
+	// proportional to the strings actually used. Workbooks without a usable EXTSST are decoded eagerly.
+	LazySST bool
+
+	// CacheSST keeps lazily decoded strings in memory, so repeated lookups are not decoded again.
+	CacheSST bool
+
+	// Limits caps the resources spent on untrusted input. The zero value imposes no limits.
+	Limits Limits
+
+	// DateLayout is the time.Format layout for cells formatted as a date. Defaults to DefaultDateLayout.
+	DateLayout string
+	// TimeLayout is the layout for cells formatted as a time of day. Defaults to DateLayout.
+	TimeLayout string
+	// DateTimeLayout is the layout for cells formatted with both a date and a time. Defaults to DateLayout.
+	DateTimeLayout string
+	// Location is the time zone the wall-clock values of date cells are placed in. Defaults to UTC.
+	Location *time.Location
+	// RawSerials renders date and time cells as their serial numbers instead of formatting them.
+	RawSerials bool
+
+	// Locale, if set, renders cells with built-in number formats the way Excel displays them in that
+	// locale: separators, currency symbol, month and day names, and the pattern behind each built-in id.
+	// Supported are "en-US", "de-DE", "ru-RU", "ja-JP" and "zh-CN". Built-in date formats then ignore
+	// DateLayout, TimeLayout and DateTimeLayout, which keep applying to user-defined formats.
+	Locale string
+
+	// SkipFilteredRows leaves out the rows an active autofilter hides, so a sheet reads the way
+	// it was displayed. The other rows keep their numbers.
+	SkipFilteredRows bool
+}
+
+// layout returns the configured layout for the given kind of date format.
+func (o *Options) layout(kind dateKind) string {
+	date := o.DateLayout
+	if date == "" {
+		date = DefaultDateLayout
+	}
+
+	switch {
+	case kind == dateKindTime && o.TimeLayout != "":
+		return o.TimeLayout
+	case kind == dateKindDateTime && o.DateTimeLayout != "":
+		return o.DateTimeLayout
+	}
+
+	return date
+}
+
+// Progress describes how far parsing has got.
+type Progress struct {
+	// Sheet is the name of the sheet being parsed, or empty for the workbook globals.
+	Sheet string
+	// Records is the number of BIFF records processed so far in the current part.
+	Records int
+	// Bytes is the number of stream bytes processed so far in the current part.
+	Bytes int64
+	// Done is true for the final report of the current part.
+	Done bool
+}
+
+// ProgressFunc receives progress reports while a workbook or sheet is parsed.
+type ProgressFunc func(Progress)
+
+// recordTracker checks for cancellation between records and feeds the progress callback.
+// It also enforces Limits.MaxDecodedBytes and resets the workbook's limit error for the new part.
+type recordTracker struct {
+	done     <-chan struct{}
+	ctx      context.Context
+	wb       *WorkBook
+	progress ProgressFunc
+	current  Progress
+}
+
+func newRecordTracker(ctx context.Context, wb *WorkBook, sheet string) *recordTracker {
+	tracker := &recordTracker{ctx: ctx, done: ctx.Done(), wb: wb, progress: wb.opts.Progress}
+	tracker.current.Sheet = sheet
+	wb.limitErr = nil
+
+	return tracker
+}
+
+// next accounts for one record of the given payload size and returns the context error
+// or a limit error, if any, so the caller can stop before decoding the record.
+func (t *recordTracker) next(size uint16) error {
+	select {
+	case <-t.done:
+		return t.ctx.Err()
+	default:
+	}
+
+	if t.wb.limitErr != nil {
+		return t.wb.limitErr
+	}
+
+	t.wb.decodedBytes += int64(size)
+	if err := t.wb.checkLimit("MaxDecodedBytes", t.wb.decodedBytes, t.wb.opts.Limits.MaxDecodedBytes); err != nil {
+		return err
+	}
+
+	t.current.Records++
+	t.current.Bytes += int64(size) + 4
+
+	if t.progress != nil && t.current.Records%progressInterval == 0 {
+		t.progress(t.current)
+	}
+
+	return nil
+}
+
+// finish returns any limit error raised by the last record, otherwise it sends the final progress report.
+func (t *recordTracker) finish() error {
+	if t.wb.limitErr != nil {
+		return t.wb.limitErr
+	}
+
+	if t.progress != nil {
+		t.current.Done = true
+		t.progress(t.current)
+	}
+
+	return nil
+}
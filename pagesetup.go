@@ -0,0 +1,202 @@
+//nolint:mnd
+package xls
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// PageSetup holds the print settings of a sheet.
+type PageSetup struct {
+	// PaperSize is Excel's paper size code, e.g. 1 for Letter and 9 for A4; 0 if the printer settings are unset.
+	PaperSize int
+	Landscape bool
+	// Scale is the print magnification in percent, used unless FitToPage is set.
+	Scale int
+	// FitToPage scales the print to FitWidth pages wide by FitHeight pages tall; 0 means no constraint.
+	FitToPage bool
+	FitWidth  int
+	FitHeight int
+	// FirstPageNumber is the number of the first page, or 0 for automatic numbering.
+	FirstPageNumber int
+	// OverThenDown prints pages left to right before top to bottom.
+	OverThenDown  bool
+	BlackAndWhite bool
+	Draft         bool
+	Copies        int
+	HorizontalDPI int
+	VerticalDPI   int
+
+	// Margins, in inches. Header and Footer are the distances of the header and footer from the page edge.
+	LeftMargin, RightMargin, TopMargin, BottomMargin float64
+	HeaderMargin, FooterMargin                       float64
+
+	CenterHorizontally bool
+	CenterVertically   bool
+	PrintGridlines     bool
+	PrintHeadings      bool
+
+	// Header and Footer are the header and footer texts, with Excel's &-codes.
+	Header, Footer string
+
+	// RowBreaks and ColBreaks are the rows and columns that start a new page.
+	RowBreaks []int
+	ColBreaks []int
+
+	// PrintArea lists the ranges to print; empty means the used range.
+	PrintArea []CellRange
+	// PrintTitles lists the rows and columns repeated on every page.
+	PrintTitles []CellRange
+}
+
+// PageSetup returns the print settings of the sheet.
+func (w *WorkSheet) PageSetup() PageSetup {
+	setup := w.pageSetup
+
+	if i := w.index(); i >= 0 {
+		if n := w.wb.builtinName(namePrintArea, i); n != nil {
+			setup.PrintArea = formulaAreas(n.formula)
+		}
+
+		if n := w.wb.builtinName(namePrintTitles, i); n != nil {
+			setup.PrintTitles = formulaAreas(n.formula)
+		}
+	}
+
+	return setup
+}
+
+// defaultPageSetup holds the settings Excel assumes when a sheet has no print records.
+var defaultPageSetup = PageSetup{
+	Scale:        100,
+	Copies:       1,
+	LeftMargin:   0.75,
+	RightMargin:  0.75,
+	TopMargin:    1,
+	BottomMargin: 1,
+	HeaderMargin: 0.5,
+	FooterMargin: 0.5,
+}
+
+// parsePageSetup decodes the print records of a sheet. It reports false for other records.
+func (w *WorkSheet) parsePageSetup(id uint16, data []byte) bool {
+	p := &w.pageSetup
+
+	switch id {
+	case 0xA1: // SETUP
+		w.setSetup(data)
+	case 0x26: // LEFTMARGIN
+		p.LeftMargin = marginRecord(data, p.LeftMargin)
+	case 0x27: // RIGHTMARGIN
+		p.RightMargin = marginRecord(data, p.RightMargin)
+	case 0x28: // TOPMARGIN
+		p.TopMargin = marginRecord(data, p.TopMargin)
+	case 0x29: // BOTTOMMARGIN
+		p.BottomMargin = marginRecord(data, p.BottomMargin)
+	case 0x83: // HCENTER
+		p.CenterHorizontally = flagRecord(data)
+	case 0x84: // VCENTER
+		p.CenterVertically = flagRecord(data)
+	case 0x2B: // PRINTGRIDLINES
+		p.PrintGridlines = flagRecord(data)
+	case 0x2A: // PRINTHEADERS
+		p.PrintHeadings = flagRecord(data)
+	case 0x14, 0x15: // HEADER, FOOTER: empty, or a string
+		var text string
+
+		if len(data) >= 3 {
+			w.strReader.Reset(data[2:])
+			text, _ = w.wb.getString(&w.strReader, binary.LittleEndian.Uint16(data))
+		}
+
+		if id == 0x14 {
+			p.Header = text
+		} else {
+			p.Footer = text
+		}
+	case 0x1B: // HORIZONTALPAGEBREAKS
+		p.RowBreaks = pageBreaks(data)
+	case 0x1A: // VERTICALPAGEBREAKS
+		p.ColBreaks = pageBreaks(data)
+	case 0x81: // WSBOOL
+		if len(data) >= 2 {
+			p.FitToPage = binary.LittleEndian.Uint16(data)&0x100 != 0
+		}
+	default:
+		return false
+	}
+
+	return true
+}
+
+// setSetup decodes a SETUP record. Without valid printer settings only the
+// scaling, page numbering, order and the header and footer margins apply.
+func (w *WorkSheet) setSetup(data []byte) {
+	if len(data) < 34 {
+		return
+	}
+
+	p := &w.pageSetup
+	flags := binary.LittleEndian.Uint16(data[10:])
+
+	p.FitWidth = int(binary.LittleEndian.Uint16(data[6:]))
+	p.FitHeight = int(binary.LittleEndian.Uint16(data[8:]))
+	p.OverThenDown = flags&0x1 != 0
+	p.BlackAndWhite = flags&0x8 != 0
+	p.Draft = flags&0x10 != 0
+	p.HeaderMargin = math.Float64frombits(binary.LittleEndian.Uint64(data[16:]))
+	p.FooterMargin = math.Float64frombits(binary.LittleEndian.Uint64(data[24:]))
+
+	if flags&0x80 != 0 {
+		p.FirstPageNumber = int(int16(binary.LittleEndian.Uint16(data[4:])))
+	}
+
+	if flags&0x4 != 0 { // fNoPls
+		return
+	}
+
+	p.PaperSize = int(binary.LittleEndian.Uint16(data))
+	p.Scale = int(binary.LittleEndian.Uint16(data[2:]))
+	p.HorizontalDPI = int(binary.LittleEndian.Uint16(data[12:]))
+	p.VerticalDPI = int(binary.LittleEndian.Uint16(data[14:]))
+	p.Copies = int(binary.LittleEndian.Uint16(data[32:]))
+
+	if flags&0x40 == 0 { // fNoOrient
+		p.Landscape = flags&0x2 == 0
+	}
+}
+
+// marginRecord decodes a margin record, keeping the previous value if the record is too short.
+func marginRecord(data []byte, previous float64) float64 {
+	if len(data) < 8 {
+		return previous
+	}
+
+	return math.Float64frombits(binary.LittleEndian.Uint64(data))
+}
+
+// flagRecord decodes a record holding a single boolean.
+func flagRecord(data []byte) bool {
+	return len(data) >= 2 && binary.LittleEndian.Uint16(data) != 0
+}
+
+// pageBreaks decodes HORIZONTALPAGEBREAKS or VERTICALPAGEBREAKS: a count followed by
+// 6-byte entries starting with the row or column that begins a new page.
+func pageBreaks(data []byte) []int {
+	if len(data) < 2 {
+		return nil
+	}
+
+	count := min(int(binary.LittleEndian.Uint16(data)), (len(data)-2)/6)
+	if count == 0 {
+		return nil
+	}
+
+	breaks := make([]int, count)
+
+	for i := range breaks {
+		breaks[i] = int(binary.LittleEndian.Uint16(data[2+6*i:]))
+	}
+
+	return breaks
+}
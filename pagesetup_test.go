@@ -0,0 +1,86 @@
+package xls
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestPageSetup(t *testing.T) {
+	t.Parallel()
+
+	wb, err := Open("testdata/bigtable.xls")
+	if err != nil {
+		t.Fatalf("failed to open XLS file: %v", err)
+	}
+
+	setup := wb.GetSheet(0).PageSetup()
+	if setup.PaperSize != 9 || setup.Landscape || setup.Scale != 100 || setup.FirstPageNumber != 1 || setup.LeftMargin != 0.7875 {
+		t.Errorf("unexpected page setup %+v", setup)
+	}
+
+	if want := `&C&"Times New Roman,Обычный"&12Страница &P`; setup.Footer != want {
+		t.Errorf("Footer = %q, want %q", setup.Footer, want)
+	}
+}
+
+// TestPrintNames decodes the Print_Area and Print_Titles names of the second sheet.
+func TestPrintNames(t *testing.T) {
+	t.Parallel()
+
+	area3d := func(rwFirst, rwLast, colFirst, colLast uint16) []byte {
+		ptg := []byte{0x3B, 0, 0}
+		for _, v := range []uint16{rwFirst, rwLast, colFirst, colLast} {
+			ptg = binary.LittleEndian.AppendUint16(ptg, v)
+		}
+
+		return ptg
+	}
+
+	name := func(code byte, rgce []byte) []byte {
+		data := []byte{0x20, 0, 0, 1}
+		data = binary.LittleEndian.AppendUint16(data, uint16(len(rgce)))
+		data = append(data, 0, 0, 2, 0, 0, 0, 0, 0, 0, code)
+
+		return append(data, rgce...)
+	}
+
+	wb := &WorkBook{}
+	sheets := []*WorkSheet{{wb: wb}, {wb: wb}}
+	wb.sheets = sheets
+
+	handleName(wb, name(namePrintArea, area3d(0, 49, 0, 5)))
+
+	titles := append([]byte{0x29, 22, 0}, area3d(0, 1, 0, 255)...)
+	titles = append(titles, area3d(0, 65535, 0, 0)...)
+	handleName(wb, name(namePrintTitles, append(titles, 0x10)))
+
+	sheet := sheets[1]
+	sheet.pageSetup = defaultPageSetup
+	sheet.parsePageSetup(0xA1, append([]byte{9, 0, 75, 0, 1, 0, 1, 0, 0, 0, 0, 0, 0x2C, 1, 0x2C, 1}, make([]byte, 18)...))
+	sheet.parsePageSetup(0x26, binary.LittleEndian.AppendUint64(nil, math.Float64bits(0.25)))
+	sheet.parsePageSetup(0x1B, []byte{2, 0, 10, 0, 0, 0, 255, 0, 20, 0, 0, 0, 255, 0})
+
+	setup := sheet.PageSetup()
+
+	if setup.PaperSize != 9 || !setup.Landscape || setup.Scale != 75 || setup.LeftMargin != 0.25 || setup.TopMargin != 1 {
+		t.Errorf("unexpected page setup %+v", setup)
+	}
+
+	if want := []int{10, 20}; !reflect.DeepEqual(setup.RowBreaks, want) {
+		t.Errorf("RowBreaks = %v, want %v", setup.RowBreaks, want)
+	}
+
+	if want := []CellRange{{0, 49, 0, 5}}; !reflect.DeepEqual(setup.PrintArea, want) {
+		t.Errorf("PrintArea = %v, want %v", setup.PrintArea, want)
+	}
+
+	if want := []CellRange{{0, 1, 0, 255}, {0, 65535, 0, 0}}; !reflect.DeepEqual(setup.PrintTitles, want) {
+		t.Errorf("PrintTitles = %v, want %v", setup.PrintTitles, want)
+	}
+
+	if setup := sheets[0].PageSetup(); setup.PrintArea != nil {
+		t.Errorf("first sheet PrintArea = %v, want none", setup.PrintArea)
+	}
+}
@@ -0,0 +1,56 @@
+//nolint:mnd
+package xls
+
+import (
+	"encoding/binary"
+	"image/color"
+)
+
+// defaultPalette is the BIFF8 color palette for the indexes 8 to 63, as 0xRRGGBB.
+// Indexes 0 to 7 repeat the first eight entries.
+var defaultPalette = [56]uint32{
+	0x000000, 0xFFFFFF, 0xFF0000, 0x00FF00, 0x0000FF, 0xFFFF00, 0xFF00FF, 0x00FFFF,
+	0x800000, 0x008000, 0x000080, 0x808000, 0x800080, 0x008080, 0xC0C0C0, 0x808080,
+	0x9999FF, 0x993366, 0xFFFFCC, 0xCCFFFF, 0x660066, 0xFF8080, 0x0066CC, 0xCCCCFF,
+	0x000080, 0xFF00FF, 0xFFFF00, 0x00FFFF, 0x800080, 0x800000, 0x008080, 0x0000FF,
+	0x00CCFF, 0xCCFFFF, 0xCCFFCC, 0xFFFF99, 0x99CCFF, 0xFF99CC, 0xCC99FF, 0xFFCC99,
+	0x3366FF, 0x33CCCC, 0x99CC00, 0xFFCC00, 0xFF9900, 0xFF6600, 0x666699, 0x969696,
+	0x003366, 0x339966, 0x003300, 0x333300, 0x993300, 0x993366, 0x333399, 0x333333,
+}
+
+// PaletteColor returns the color with the given palette index, honoring a PALETTE record.
+// The fixed colors 0 to 7 are never replaced. It reports false for the system and automatic
+// colors, which depend on the viewer.
+func (wb *WorkBook) PaletteColor(idx int) (color.RGBA, bool) {
+	if idx < 0 || idx >= 8+len(defaultPalette) {
+		return color.RGBA{}, false
+	}
+
+	var rgb uint32
+
+	switch {
+	case idx < 8:
+		rgb = defaultPalette[idx]
+	case idx-8 < len(wb.palette):
+		rgb = wb.palette[idx-8]
+	default:
+		rgb = defaultPalette[idx-8]
+	}
+
+	return color.RGBA{R: byte(rgb >> 16), G: byte(rgb >> 8), B: byte(rgb), A: 0xFF}, true
+}
+
+// handlePalette decodes a PALETTE record, which replaces the colors from index 8 on.
+func handlePalette(wb *WorkBook, data []byte) {
+	if len(data) < 2 {
+		return
+	}
+
+	count := min(int(binary.LittleEndian.Uint16(data)), (len(data)-2)/4, len(defaultPalette))
+	wb.palette = make([]uint32, count)
+
+	for i := range wb.palette {
+		c := data[2+4*i:]
+		wb.palette[i] = uint32(c[0])<<16 | uint32(c[1])<<8 | uint32(c[2])
+	}
+}
@@ -1,13 +1,11 @@
 package xls
 
 import (
-	"bytes"
 	"encoding/binary"
-	"io"
 )
 
 // Handler function type for BIFF records.
-type recordHandler func(wb *WorkBook, data []byte, prevBOF *bof, offsetPre int) (offset int, after *bof, afterUsing *bof)
+type recordHandler func(wb *WorkBook, data []byte)
 
 var recordHandlers = map[uint16]recordHandler{
 	0x809: handleBOF,
@@ -17,172 +15,160 @@ var recordHandlers = map[uint16]recordHandler{
 	0x031: handleFont,
 	0x41E: handleFormat,
 	0x22:  handleDateMode,
+	0xff:  handleExtSST,
+	0x18:  handleName,
+	0x92:  handlePalette,
+	0x12:  handleProtect,
+	0x19:  handleWindowProtect,
+	0x13:  handlePassword,
+	0x1AF: handleProt4Rev,
+	0x1BC: handleProt4RevPass,
+	0xEB:  handleDrawingGroup,
+	0x1AE: handleSupBook,
+	0x17:  handleExternSheet,
+	0x23:  handleExternName,
+	0x2F:  handleFilePass,
 }
 
-type sstParser struct {
-	wb       *WorkBook
-	reader   *bytes.Reader
-	sstIndex int
-	strCount int
-}
-
-func (wb *WorkBook) parseBof(buf io.ReadSeeker, current *bof, previous *bof, sstOffsetIn int) (sstOffsetOut int, newPrev *bof, newCurr *bof) {
-	data := make([]byte, current.Size)
-	if err := binary.Read(buf, binary.LittleEndian, data); err != nil {
-		panic(err)
-	}
-
-	sstOffsetOut = sstOffsetIn
-	newPrev = current
-	newCurr = previous
-
+// parseBof decodes one record of the workbook globals; pos is the stream offset of its payload.
+// data is only valid during the call.
+func (wb *WorkBook) parseBof(current bof, data []byte, pos int64) {
 	switch current.ID {
 	case 0xfc: // SST
-		parser := &sstParser{wb: wb}
-		parser.parseSST(data)
-		wb.sstParser = parser
-		sstOffsetOut = parser.sstIndex
+		wb.drawingOpen = false
+		wb.addSST(data, pos)
 
+		return
 	case 0x3c: // CONTINUE
-		if previous.ID == 0xfc && wb.sstParser != nil {
-			wb.sstParser.parseContinue(data)
-			sstOffsetOut = wb.sstParser.sstIndex
-
-			// Continue treating `previous` as the SST block, and current as CONTINUE
-			newPrev = previous
-			newCurr = current
+		if wb.drawingOpen {
+			wb.drawingGroup = append(wb.drawingGroup, data...)
+		} else {
+			wb.addSSTContinue(data, pos)
 		}
 
-	default:
-		if handler := recordHandlers[current.ID]; handler != nil {
-			offset, maybeNewPrev, maybeNewCurr := handler(wb, data, previous, sstOffsetIn)
+		return
+	}
 
-			sstOffsetOut = offset
+	if wb.sstTable != nil {
+		wb.sstTable.open = false
+	}
 
-			if maybeNewPrev != nil {
-				newPrev = maybeNewPrev
-			}
+	wb.drawingOpen = false
 
-			if maybeNewCurr != nil {
-				newCurr = maybeNewCurr
-			}
-		}
+	if handler := recordHandlers[current.ID]; handler != nil {
+		handler(wb, data)
 	}
-
-	return sstOffsetOut, newPrev, newCurr
 }
 
-func handleBOF(workBook *WorkBook, data []byte, _ *bof, offsetPre int) (int, *bof, *bof) {
-	buf := bytes.NewReader(data)
-	bif := new(biffHeader)
-	binary.Read(buf, binary.LittleEndian, bif)
+func handleBOF(workBook *WorkBook, data []byte) {
+	if len(data) < 4 {
+		return
+	}
 
-	if bif.Ver != 0x600 {
+	if binary.LittleEndian.Uint16(data) != 0x600 {
 		workBook.Is5ver = true
 	}
 
-	workBook.Type = bif.Type
-
-	return offsetPre, nil, nil
+	workBook.Type = binary.LittleEndian.Uint16(data[2:])
 }
 
-func handleCodepage(wb *WorkBook, data []byte, _ *bof, offsetPre int) (int, *bof, *bof) {
-	buf := bytes.NewReader(data)
-	binary.Read(buf, binary.LittleEndian, &wb.Codepage)
-
-	return offsetPre, nil, nil
+func handleCodepage(wb *WorkBook, data []byte) {
+	if len(data) >= 2 {
+		wb.Codepage = binary.LittleEndian.Uint16(data)
+	}
 }
 
-func handleBoundSheet(wb *WorkBook, data []byte, _ *bof, offsetPre int) (int, *bof, *bof) {
-	buf := bytes.NewReader(data)
-	bs := new(boundsheet)
-	binary.Read(buf, binary.LittleEndian, bs)
-	wb.addSheet(bs, buf)
+func handleBoundSheet(wb *WorkBook, data []byte) {
+	if len(data) < 7 {
+		return
+	}
 
-	return offsetPre, nil, nil
+	bs := &boundsheet{
+		Filepos: binary.LittleEndian.Uint32(data),
+		Visible: data[4],
+		Type:    data[5],
+		Name:    data[6],
+	}
+	wb.strReader.Reset(data[7:])
+	wb.addSheet(bs, &wb.strReader)
 }
 
-func handleXF(workBook *WorkBook, data []byte, _ *bof, offsetPre int) (int, *bof, *bof) {
-	buf := bytes.NewReader(data)
-
+func handleXF(workBook *WorkBook, data []byte) {
 	if workBook.Is5ver {
-		xf := new(Xf5)
-		binary.Read(buf, binary.LittleEndian, xf)
-		workBook.addXf(xf)
+		if len(data) < 16 {
+			return
+		}
+
+		workBook.addXf(&Xf5{
+			Font:      binary.LittleEndian.Uint16(data),
+			Format:    binary.LittleEndian.Uint16(data[2:]),
+			Type:      binary.LittleEndian.Uint16(data[4:]),
+			Align:     binary.LittleEndian.Uint16(data[6:]),
+			Color:     binary.LittleEndian.Uint16(data[8:]),
+			Fill:      binary.LittleEndian.Uint16(data[10:]),
+			Border:    binary.LittleEndian.Uint16(data[12:]),
+			LineStyle: binary.LittleEndian.Uint16(data[14:]),
+		})
 	} else {
-		xf := new(Xf8)
-		binary.Read(buf, binary.LittleEndian, xf)
-		workBook.addXf(xf)
-	}
+		if len(data) < 20 {
+			return
+		}
 
-	return offsetPre, nil, nil
+		workBook.addXf(&Xf8{
+			Font:        binary.LittleEndian.Uint16(data),
+			Format:      binary.LittleEndian.Uint16(data[2:]),
+			Type:        binary.LittleEndian.Uint16(data[4:]),
+			Align:       data[6],
+			Rotation:    data[7],
+			Ident:       data[8],
+			UsedAttr:    data[9],
+			LineStyle:   binary.LittleEndian.Uint32(data[10:]),
+			LineColor:   binary.LittleEndian.Uint32(data[14:]),
+			GroundColor: binary.LittleEndian.Uint16(data[18:]),
+		})
+	}
 }
 
-func handleFont(workBook *WorkBook, data []byte, _ *bof, offsetPre int) (int, *bof, *bof) {
-	buf := bytes.NewReader(data)
-	f := new(FontInfo)
-	binary.Read(buf, binary.LittleEndian, f)
-	workBook.addFont(f, buf)
+func handleFont(workBook *WorkBook, data []byte) {
+	if len(data) < 15 {
+		return
+	}
 
-	return offsetPre, nil, nil
+	f := &FontInfo{
+		Height:     binary.LittleEndian.Uint16(data),
+		Flag:       binary.LittleEndian.Uint16(data[2:]),
+		Color:      binary.LittleEndian.Uint16(data[4:]),
+		Bold:       binary.LittleEndian.Uint16(data[6:]),
+		Escapement: binary.LittleEndian.Uint16(data[8:]),
+		Underline:  data[10],
+		Family:     data[11],
+		Charset:    data[12],
+		Notused:    data[13],
+		NameB:      data[14],
+	}
+	workBook.strReader.Reset(data[15:])
+	workBook.addFont(f, &workBook.strReader)
 }
 
-func handleFormat(workBook *WorkBook, data []byte, _ *bof, offsetPre int) (int, *bof, *bof) {
-	buf := bytes.NewReader(data)
+func handleFormat(workBook *WorkBook, data []byte) {
+	if len(data) < 4 {
+		return
+	}
+
 	format := new(Format)
-	binary.Read(buf, binary.LittleEndian, &format.Head)
-	format.str, _ = workBook.getString(buf, format.Head.Size)
+	format.Head.Index = binary.LittleEndian.Uint16(data)
+	format.Head.Size = binary.LittleEndian.Uint16(data[2:])
+	workBook.strReader.Reset(data[4:])
+	format.str, _ = workBook.getString(&workBook.strReader, format.Head.Size)
 	workBook.addFormat(format)
-
-	return offsetPre, nil, nil
-}
-
-func handleDateMode(workBook *WorkBook, data []byte, _ *bof, offsetPre int) (int, *bof, *bof) {
-	buf := bytes.NewReader(data)
-	binary.Read(buf, binary.LittleEndian, &workBook.dateMode)
-
-	return offsetPre, nil, nil
-}
-
-func (p *sstParser) parseSST(data []byte) {
-	p.reader = bytes.NewReader(data)
-
-	info := new(SstInfo)
-	binary.Read(p.reader, binary.LittleEndian, info)
-
-	p.wb.sst = make([]string, info.Count)
-	p.strCount = int(info.Count)
-
-	p.parseStrings()
 }
 
-func (p *sstParser) parseContinue(data []byte) {
-	p.reader = bytes.NewReader(data)
-	p.parseStrings()
+func handleDateMode(workBook *WorkBook, data []byte) {
+	if len(data) >= 2 {
+		workBook.dateMode = binary.LittleEndian.Uint16(data)
+	}
 }
 
-func (p *sstParser) parseStrings() {
-	for p.sstIndex < p.strCount {
-		var size uint16
-		var err error
-
-		if p.wb.continueUtf16 > 0 {
-			size = p.wb.continueUtf16
-			p.wb.continueUtf16 = 0
-		} else {
-			err = binary.Read(p.reader, binary.LittleEndian, &size)
-			if err != nil {
-				break
-			}
-		}
-
-		str, err := p.wb.getString(p.reader, size)
-		p.wb.sst[p.sstIndex] += str
-
-		if err == io.EOF {
-			break
-		}
-
-		p.sstIndex++
-	}
+func handleExtSST(workBook *WorkBook, data []byte) {
+	workBook.addExtSST(data)
 }
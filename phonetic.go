@@ -0,0 +1,101 @@
+package xls
+
+import (
+	"encoding/binary"
+	"unicode/utf16"
+)
+
+// Phonetic is the phonetic reading (furigana) attached to a string.
+type Phonetic struct {
+	// Text is the whole reading.
+	Text string
+	// Font is the font of the reading, or nil if the file does not define it.
+	Font *Font
+	// Type is the character set of the reading: 0 narrow katakana, 1 wide katakana, 2 hiragana, 3 any.
+	Type byte
+	// Alignment is the alignment of the reading above the text: 0 none, 1 left, 2 center, 3 distributed.
+	Alignment byte
+	// Runs map parts of the reading to the characters of the string they read.
+	Runs []PhoneticRun
+}
+
+// PhoneticRun is the reading of a part of the string.
+type PhoneticRun struct {
+	// Reading is the part of Phonetic.Text for this run.
+	Reading string
+	// Base is the part of the string that is read.
+	Base string
+	// BaseStart is the offset of Base in the string, in characters.
+	BaseStart int
+}
+
+// Phonetic returns the phonetic reading of the string in column i, or nil if it has none.
+func (r *Row) Phonetic(i int) *Phonetic {
+	if ch, ok := r.cell(i).(*LabelsstCol); ok {
+		return r.wb.sstEntry(ch.Sst).phonetic
+	}
+
+	return nil
+}
+
+// parseExtRst decodes the ExtRst structure following the characters of a shared string.
+// base holds the UTF-16 characters of the string itself.
+func (wb *WorkBook) parseExtRst(data []byte, base []uint16) *Phonetic {
+	// reserved, cb, then Phs (ifnt, ph) and RPHSSub (crun, cch, cchCharacters)
+	if len(data) < 14 {
+		return nil
+	}
+
+	body := data[4:min(4+int(binary.LittleEndian.Uint16(data[2:])), len(data))]
+	if len(body) < 10 {
+		return nil
+	}
+
+	ph := binary.LittleEndian.Uint16(body[2:])
+	phonetic := &Phonetic{
+		Font:      wb.font(binary.LittleEndian.Uint16(body)),
+		Type:      byte(ph & 0x3),
+		Alignment: byte(ph >> 2 & 0x3),
+	}
+
+	count := int(binary.LittleEndian.Uint16(body[4:]))
+	size := int(binary.LittleEndian.Uint16(body[8:]))
+
+	text := make([]uint16, 0, min(size, (len(body)-10)/2))
+	for i := 10; len(text) < size && i+2 <= len(body); i += 2 {
+		text = append(text, binary.LittleEndian.Uint16(body[i:]))
+	}
+
+	phonetic.Text = string(utf16.Decode(text))
+
+	// The PhRuns close the structure, 6 bytes each: ichFirst, ichMom, cchMom
+	count = min(count, (len(body)-10-2*len(text))/6)
+	runs := body[len(body)-6*count:]
+
+	for i := 0; i < count; i++ {
+		first := int(binary.LittleEndian.Uint16(runs[6*i:]))
+		mom := int(binary.LittleEndian.Uint16(runs[6*i+2:]))
+		momSize := int(binary.LittleEndian.Uint16(runs[6*i+4:]))
+
+		// A run reads up to where the next one starts
+		end := len(text)
+		if i+1 < count {
+			end = int(binary.LittleEndian.Uint16(runs[6*i+6:]))
+		}
+
+		first, end = min(first, len(text)), min(max(end, first), len(text))
+		mom, momEnd := min(mom, len(base)), min(mom+momSize, len(base))
+
+		phonetic.Runs = append(phonetic.Runs, PhoneticRun{
+			Reading:   string(utf16.Decode(text[first:end])),
+			Base:      string(utf16.Decode(base[mom:momEnd])),
+			BaseStart: len(utf16.Decode(base[:mom])),
+		})
+	}
+
+	if phonetic.Text == "" && len(phonetic.Runs) == 0 {
+		return nil
+	}
+
+	return phonetic
+}
@@ -0,0 +1,198 @@
+//nolint:mnd
+package xls
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Picture is an image placed on a sheet.
+type Picture struct {
+	// Name and Description are the shape name and the alternative text, if set.
+	Name        string
+	Description string
+	// From and To locate the top-left and bottom-right corners of the picture.
+	From, To CellAnchor
+	// MIME is the media type of Data: image/png, image/jpeg, image/bmp, image/tiff,
+	// image/x-emf, image/x-wmf or image/x-pict.
+	MIME string
+	// Data holds the image file. Bitmaps get the file header a DIB lacks, and metafiles are
+	// decompressed; WMF data has no placeable header.
+	Data []byte
+}
+
+// CellAnchor locates a corner of a shape: a cell, and the offset into it as fractions
+// of the cell's width and height.
+type CellAnchor struct {
+	Row, Col int
+	DX, DY   float64
+}
+
+// maxMetafileSize caps the size of a decompressed metafile when Limits.MaxDecodedBytes is not set.
+const maxMetafileSize = 256 << 20
+
+// Pictures returns the pictures of the sheet, in drawing order. It fails if an image cannot be decoded.
+// A metafile larger than Limits.MaxDecodedBytes, or 256 MB when it is not set, fails with a *LimitError.
+func (w *WorkSheet) Pictures() ([]Picture, error) {
+	var pictures []Picture
+
+	blips := w.wb.blipStore()
+
+	maxSize := w.wb.opts.Limits.MaxDecodedBytes
+	if maxSize <= 0 {
+		maxSize = maxMetafileSize
+	}
+
+	for _, shape := range escherShapes(w.drawing) {
+		if shape.pib <= 0 || shape.pib > len(blips) || blips[shape.pib-1] == nil {
+			continue
+		}
+
+		mime, data, err := decodeBSE(blips[shape.pib-1], maxSize)
+		if err != nil {
+			return nil, fmt.Errorf("xls: picture %d of sheet %q: %w", shape.pib, w.Name, err)
+		}
+
+		if data == nil {
+			continue
+		}
+
+		p := Picture{Name: shape.name, Description: shape.description, MIME: mime, Data: data}
+		p.From, p.To = shape.cells()
+
+		pictures = append(pictures, p)
+	}
+
+	return pictures, nil
+}
+
+// cells returns the corners of the shape from its anchor: flags, then the left column,
+// its offset in 1/1024 of the column width, the top row, its offset in 1/256 of the row
+// height, and the same for the right column and bottom row.
+func (s escherShape) cells() (from, to CellAnchor) {
+	a := s.anchor
+	if a == nil {
+		return from, to
+	}
+
+	u16 := func(i int) int { return int(binary.LittleEndian.Uint16(a[i:])) }
+	from = CellAnchor{Col: u16(2), DX: float64(u16(4)) / 1024, Row: u16(6), DY: float64(u16(8)) / 256}
+	to = CellAnchor{Col: u16(10), DX: float64(u16(12)) / 1024, Row: u16(14), DY: float64(u16(16)) / 256}
+
+	return from, to
+}
+
+// decodeBSE returns the image embedded in a BSE record: the BLIP types, a UID, a tag,
+// sizes, a reference count, a delay stream offset, the length of a name, the name and then
+// the BLIP record. It returns nil data for pictures stored outside the BSE.
+// Metafiles may decompress to at most maxSize bytes.
+func decodeBSE(bse []byte, maxSize int64) (mime string, data []byte, err error) {
+	if len(bse) < 36 || len(bse) < 36+int(bse[33]) {
+		return "", nil, nil
+	}
+
+	escherRecords(bse[36+int(bse[33]):], func(h escherHeader, body []byte) {
+		if data == nil && err == nil {
+			mime, data, err = decodeBlip(h, body, maxSize)
+		}
+	})
+
+	return mime, data, err
+}
+
+// decodeBlip decodes a BLIP record. Its instance tells whether it has a second UID.
+func decodeBlip(h escherHeader, body []byte, maxSize int64) (mime string, data []byte, err error) {
+	off := 16
+	if h.instance&1 != 0 {
+		off += 16
+	}
+
+	switch h.typ {
+	case 0xF01A, 0xF01B, 0xF01C: // EMF, WMF, PICT
+		mime = map[uint16]string{0xF01A: "image/x-emf", 0xF01B: "image/x-wmf", 0xF01C: "image/x-pict"}[h.typ]
+		data, err = decodeMetafile(body, off, maxSize)
+
+		return mime, data, err
+	case 0xF01D, 0xF02A: // JPEG, CMYK JPEG
+		mime = "image/jpeg"
+	case 0xF01E:
+		mime = "image/png"
+	case 0xF01F:
+		mime = "image/bmp"
+	case 0xF029:
+		mime = "image/tiff"
+	default:
+		return "", nil, nil
+	}
+
+	// bitmaps have a tag byte after the UIDs
+	if len(body) < off+1 {
+		return "", nil, io.ErrUnexpectedEOF
+	}
+
+	data = bytes.Clone(body[off+1:])
+	if h.typ == 0xF01F {
+		data = dibToBMP(data)
+	}
+
+	return mime, data, nil
+}
+
+// decodeMetafile decodes the data of a metafile BLIP after its UIDs: the uncompressed size,
+// bounds, size in EMUs, the saved size, the compression (0 for deflate) and a filter byte.
+// It fails with a *LimitError if the uncompressed size is above maxSize.
+func decodeMetafile(body []byte, off int, maxSize int64) ([]byte, error) {
+	if len(body) < off+34 {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	size := int64(binary.LittleEndian.Uint32(body[off:]))
+	data := body[off+34:]
+
+	if size > maxSize {
+		return nil, &LimitError{Limit: "MaxDecodedBytes", Max: maxSize, Value: size}
+	}
+
+	if body[off+32] != 0 {
+		return bytes.Clone(data), nil
+	}
+
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(io.LimitReader(r, size))
+}
+
+// dibToBMP prefixes a device-independent bitmap with the BITMAPFILEHEADER of a .bmp file.
+// The pixels start after the info header, the color masks and the color table.
+func dibToBMP(dib []byte) []byte {
+	if len(dib) < 40 {
+		return dib
+	}
+
+	headerSize := binary.LittleEndian.Uint32(dib)
+	bitCount := binary.LittleEndian.Uint16(dib[14:])
+	colors := binary.LittleEndian.Uint32(dib[32:])
+
+	if colors == 0 && bitCount <= 8 {
+		colors = 1 << bitCount
+	}
+
+	offset := 14 + headerSize + 4*colors
+	if headerSize == 40 && binary.LittleEndian.Uint32(dib[16:]) == 3 { // BI_BITFIELDS
+		offset += 12
+	}
+
+	bmp := make([]byte, 14, 14+len(dib))
+	copy(bmp, "BM")
+	binary.LittleEndian.PutUint32(bmp[2:], uint32(14+len(dib)))
+	binary.LittleEndian.PutUint32(bmp[10:], offset)
+
+	return append(bmp, dib...)
+}
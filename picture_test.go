@@ -0,0 +1,199 @@
+package xls
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+	"unicode/utf16"
+)
+
+// escherRecord encodes an Office Drawing record.
+func escherRecord(verInstance, typ uint16, body ...[]byte) []byte {
+	data := bytes.Join(body, nil)
+
+	res := binary.LittleEndian.AppendUint16(nil, verInstance)
+	res = binary.LittleEndian.AppendUint16(res, typ)
+	res = binary.LittleEndian.AppendUint32(res, uint32(len(data)))
+
+	return append(res, data...)
+}
+
+// TestPictures decodes a PNG and a compressed EMF from the BLIP store, placed by a picture
+// shape with a name and by a shape inside an anchored group.
+func TestPictures(t *testing.T) {
+	t.Parallel()
+
+	png := append([]byte("\x89PNG\r\n\x1a\n"), "pixels"...)
+	emf := bytes.Repeat([]byte("EMF record "), 20)
+
+	var deflated bytes.Buffer
+
+	zw := zlib.NewWriter(&deflated)
+	zw.Write(emf)
+	zw.Close()
+
+	bse := func(btWin32 byte, blip []byte) []byte {
+		data := make([]byte, 36)
+		data[0] = btWin32
+
+		return escherRecord(0x2|uint16(btWin32)<<4, 0xF007, data, blip)
+	}
+
+	pngBlip := escherRecord(0x6E0<<4, 0xF01E, make([]byte, 17), png)
+
+	metafile := binary.LittleEndian.AppendUint32(nil, uint32(len(emf)))
+	metafile = append(metafile, make([]byte, 24)...)
+	metafile = binary.LittleEndian.AppendUint32(metafile, uint32(deflated.Len()))
+	metafile = append(metafile, 0, 0xFE)
+	emfBlip := escherRecord(0x3D5<<4, 0xF01A, make([]byte, 32), metafile, deflated.Bytes())
+
+	group := escherRecord(0xF, 0xF000,
+		escherRecord(0, 0xF006, make([]byte, 16)),
+		escherRecord(0x3F, 0xF001, bse(6, pngBlip), escherRecord(0x2, 0xF007, make([]byte, 36)), bse(2, emfBlip)))
+
+	wb := &WorkBook{}
+	wb.parseBof(bof{ID: 0xEB}, group[:40], 0)
+	wb.parseBof(bof{ID: 0x3C}, group[40:], 0)
+
+	anchor := func(col, dx, row, dy, col2, dx2, row2, dy2 uint16) []byte {
+		data := make([]byte, 2)
+		for _, v := range []uint16{col, dx, row, dy, col2, dx2, row2, dy2} {
+			data = binary.LittleEndian.AppendUint16(data, v)
+		}
+
+		return escherRecord(0, 0xF010, data)
+	}
+
+	name := utf16.Encode([]rune("Photo\x00"))
+	nameData := make([]byte, 0, 2*len(name))
+
+	for _, c := range name {
+		nameData = binary.LittleEndian.AppendUint16(nameData, c)
+	}
+
+	props := []byte{0x04, 0x41, 1, 0, 0, 0, 0x80, 0x83}
+	props = binary.LittleEndian.AppendUint32(props, uint32(len(nameData)))
+
+	photo := escherRecord(0xF, 0xF004,
+		escherRecord(0x4B2, 0xF00A, make([]byte, 8)),
+		escherRecord(0x23, 0xF00B, props, nameData),
+		anchor(1, 512, 2, 64, 3, 0, 8, 128))
+
+	grouped := escherRecord(0xF, 0xF004, escherRecord(0x13, 0xF00B, []byte{0x04, 0x41, 3, 0, 0, 0}))
+
+	drawing := escherRecord(0xF, 0xF002,
+		escherRecord(0, 0xF008, make([]byte, 8)),
+		escherRecord(0xF, 0xF003,
+			escherRecord(0xF, 0xF004, escherRecord(0, 0xF009, make([]byte, 16))),
+			photo,
+			escherRecord(0xF, 0xF003,
+				escherRecord(0xF, 0xF004, anchor(5, 0, 10, 0, 7, 0, 12, 0)),
+				grouped)))
+
+	sheet := &WorkSheet{wb: wb, Name: "Products"}
+	sheet.parseBof(bof{ID: 0xEC}, drawing[:60], nil)
+	sheet.parseBof(bof{ID: 0xEC}, drawing[60:], nil)
+
+	got, err := sheet.Pictures()
+	if err != nil {
+		t.Fatalf("Pictures() failed: %v", err)
+	}
+
+	want := []Picture{
+		{
+			Name: "Photo",
+			From: CellAnchor{Row: 2, Col: 1, DX: 0.5, DY: 0.25},
+			To:   CellAnchor{Row: 8, Col: 3, DY: 0.5},
+			MIME: "image/png",
+			Data: png,
+		},
+		{
+			From: CellAnchor{Row: 10, Col: 5},
+			To:   CellAnchor{Row: 12, Col: 7},
+			MIME: "image/x-emf",
+			Data: emf,
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Pictures() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDIBToBMP(t *testing.T) {
+	t.Parallel()
+
+	dib := make([]byte, 40+8+4)
+	binary.LittleEndian.PutUint32(dib, 40)
+	binary.LittleEndian.PutUint16(dib[14:], 1)
+
+	bmp := dibToBMP(dib)
+	if string(bmp[:2]) != "BM" || binary.LittleEndian.Uint32(bmp[2:]) != uint32(len(bmp)) {
+		t.Fatalf("bad file header % x", bmp[:14])
+	}
+
+	// two palette entries for a 1-bit bitmap
+	if got := binary.LittleEndian.Uint32(bmp[10:]); got != 14+40+8 {
+		t.Errorf("pixel offset = %d, want %d", got, 14+40+8)
+	}
+}
+
+// TestMetafileLimit rejects metafiles whose uncompressed size is above the limit, whether
+// Limits.MaxDecodedBytes sets it or not.
+func TestMetafileLimit(t *testing.T) {
+	t.Parallel()
+
+	var deflated bytes.Buffer
+
+	zw := zlib.NewWriter(&deflated)
+	zw.Write(make([]byte, 1024))
+	zw.Close()
+
+	metafile := func(size uint32) []byte {
+		data := binary.LittleEndian.AppendUint32(make([]byte, 16), size)
+		data = append(data, make([]byte, 28)...)
+		data = append(data, 0, 0xFE)
+
+		return append(data, deflated.Bytes()...)
+	}
+
+	for _, tt := range []struct {
+		size    uint32
+		maxSize int64
+		limited bool
+	}{
+		{1024, 1024, false},
+		{1024, 512, true},
+		{0xFFFFFFFF, maxMetafileSize, true},
+	} {
+		data, err := decodeMetafile(metafile(tt.size), 16, tt.maxSize)
+		if tt.limited {
+			if !errors.Is(err, ErrLimitExceeded) {
+				t.Errorf("size %d, max %d: err = %v, want ErrLimitExceeded", tt.size, tt.maxSize, err)
+			}
+
+			continue
+		}
+
+		if err != nil || len(data) != int(tt.size) {
+			t.Errorf("size %d, max %d: got %d bytes, %v", tt.size, tt.maxSize, len(data), err)
+		}
+	}
+}
+
+func TestNoPictures(t *testing.T) {
+	t.Parallel()
+
+	wb, err := Open("testdata/times.xls")
+	if err != nil {
+		t.Fatalf("failed to open XLS file: %v", err)
+	}
+
+	pictures, err := wb.GetSheet(0).Pictures()
+	if err != nil || len(pictures) != 0 {
+		t.Errorf("Pictures() = %v, %v, want none", pictures, err)
+	}
+}
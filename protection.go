@@ -0,0 +1,261 @@
+//nolint:mnd
+package xls
+
+import (
+	"encoding/binary"
+)
+
+// WorkbookProtection holds the workbook-level protection settings.
+type WorkbookProtection struct {
+	// Structure is set when sheets cannot be added, moved, deleted, hidden or renamed.
+	Structure bool
+	// Windows is set when the workbook windows cannot be moved, resized or closed.
+	Windows bool
+	// Password is the verifier of the password protecting the structure and windows, or 0 if there is none.
+	Password uint16
+	// Revisions is set when the change tracking of a shared workbook cannot be turned off.
+	Revisions bool
+	// RevisionsPassword is the verifier of the password protecting the change tracking, or 0 if there is none.
+	RevisionsPassword uint16
+}
+
+// CheckPassword reports whether password unlocks the workbook structure and windows.
+func (p WorkbookProtection) CheckPassword(password string) bool {
+	return PasswordVerifier(password) == p.Password
+}
+
+// SheetProtection holds the protection settings of a sheet. It comes from the PROTECT,
+// PASSWORD, OBJPROTECT, SCENPROTECT, FEATHEADR and FEAT records.
+type SheetProtection struct {
+	// Protected is set when the locked cells of the sheet cannot be edited.
+	Protected bool
+	// Objects and Scenarios are set when the drawing objects and the scenarios are protected.
+	Objects   bool
+	Scenarios bool
+	// Password is the verifier of the sheet password, or 0 if there is none.
+	Password uint16
+	// Allowed lists what users may still do while the sheet is protected.
+	Allowed SheetActions
+	// Ranges lists the ranges users may edit while the sheet is protected, possibly with their own password.
+	Ranges []ProtectedRange
+}
+
+// CheckPassword reports whether password unprotects the sheet.
+func (p SheetProtection) CheckPassword(password string) bool {
+	return PasswordVerifier(password) == p.Password
+}
+
+// SheetActions tells which actions a protected sheet still allows.
+type SheetActions struct {
+	EditObjects         bool
+	EditScenarios       bool
+	FormatCells         bool
+	FormatColumns       bool
+	FormatRows          bool
+	InsertColumns       bool
+	InsertRows          bool
+	InsertHyperlinks    bool
+	DeleteColumns       bool
+	DeleteRows          bool
+	SelectLockedCells   bool
+	Sort                bool
+	AutoFilter          bool
+	PivotTables         bool
+	SelectUnlockedCells bool
+}
+
+// defaultSheetActions are the actions allowed on a protected sheet without a FEATHEADR record.
+var defaultSheetActions = SheetActions{SelectLockedCells: true, SelectUnlockedCells: true}
+
+// ProtectedRange is a range of a protected sheet that users may edit.
+type ProtectedRange struct {
+	Title string
+	Refs  []CellRange
+	// Password is the verifier of the range password, or 0 if the range needs none.
+	Password uint16
+}
+
+// CheckPassword reports whether password unlocks the range.
+func (r ProtectedRange) CheckPassword(password string) bool {
+	return PasswordVerifier(password) == r.Password
+}
+
+// PasswordVerifier returns the 16-bit verifier Excel stores for a protection password,
+// or 0 for an empty password. Only the first 15 characters count, each reduced to a single byte.
+// The verifier is a weak hash: other passwords match it too.
+func PasswordVerifier(password string) uint16 {
+	var chars []byte
+
+	for _, r := range password {
+		if len(chars) == 15 {
+			break
+		}
+
+		// Excel takes the low byte of each UTF-16 character, or the high byte if the low one is 0
+		c := byte(r)
+		if c == 0 {
+			c = byte(r >> 8)
+		}
+
+		chars = append(chars, c)
+	}
+
+	if len(chars) == 0 {
+		return 0
+	}
+
+	var v uint16
+	for i := len(chars) - 1; i >= 0; i-- {
+		v = rotateVerifier(v) ^ uint16(chars[i])
+	}
+
+	return rotateVerifier(v) ^ uint16(len(chars)) ^ 0xCE4B
+}
+
+// rotateVerifier rotates the 15 low bits of v left by one.
+func rotateVerifier(v uint16) uint16 {
+	return v>>14&1 | v<<1&0x7FFF
+}
+
+// Protection returns the workbook-level protection settings.
+func (wb *WorkBook) Protection() WorkbookProtection {
+	return wb.protection
+}
+
+// Protection returns the protection settings of the sheet.
+func (w *WorkSheet) Protection() SheetProtection {
+	return w.protection
+}
+
+func handleProtect(wb *WorkBook, data []byte) {
+	wb.protection.Structure = flagRecord(data)
+}
+
+func handleWindowProtect(wb *WorkBook, data []byte) {
+	wb.protection.Windows = flagRecord(data)
+}
+
+func handlePassword(wb *WorkBook, data []byte) {
+	wb.protection.Password = verifierRecord(data)
+}
+
+func handleProt4Rev(wb *WorkBook, data []byte) {
+	wb.protection.Revisions = flagRecord(data)
+}
+
+func handleProt4RevPass(wb *WorkBook, data []byte) {
+	wb.protection.RevisionsPassword = verifierRecord(data)
+}
+
+// handleFilePass decodes a FILEPASS record, which marks the records that follow as encrypted:
+// the encryption type, 0 for XOR obfuscation and 1 for RC4, and for RC4 the major version
+// of its header, 1 for plain RC4 and above for RC4 with CryptoAPI.
+func handleFilePass(wb *WorkBook, data []byte) {
+	switch {
+	case len(data) >= 2 && binary.LittleEndian.Uint16(data) == 0:
+		wb.encryption = "xor"
+	case len(data) >= 4 && binary.LittleEndian.Uint16(data[2:]) == 1:
+		wb.encryption = "rc4"
+	default:
+		wb.encryption = "cryptoapi"
+	}
+}
+
+// parseProtection decodes the protection records of a sheet. It reports false for other records.
+func (w *WorkSheet) parseProtection(id uint16, data []byte) bool {
+	p := &w.protection
+
+	switch id {
+	case 0x12: // PROTECT
+		p.Protected = flagRecord(data)
+	case 0x13: // PASSWORD
+		p.Password = verifierRecord(data)
+	case 0x63: // OBJPROTECT
+		p.Objects = flagRecord(data)
+	case 0xDD: // SCENPROTECT
+		p.Scenarios = flagRecord(data)
+	case 0x867: // FEATHEADR, SHEETPROTECTION when it holds the enhanced protection flags
+		// future record header, isf, reserved byte, cbHdrData and then the flags if cbHdrData is -1
+		if len(data) >= 23 && binary.LittleEndian.Uint16(data[12:]) == isfProtection &&
+			binary.LittleEndian.Uint32(data[15:]) == 0xFFFFFFFF {
+			p.Allowed = sheetActions(binary.LittleEndian.Uint32(data[19:]))
+		}
+	case 0x868: // FEAT
+		w.addProtectedRange(data)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// isfProtection is the shared feature type of protected ranges and of the enhanced protection flags.
+const isfProtection = 2
+
+// sheetActions decodes the flags of an EnhancedProtection structure.
+func sheetActions(flags uint32) SheetActions {
+	bit := func(i uint) bool { return flags&(1<<i) != 0 }
+
+	return SheetActions{
+		EditObjects:         bit(0),
+		EditScenarios:       bit(1),
+		FormatCells:         bit(2),
+		FormatColumns:       bit(3),
+		FormatRows:          bit(4),
+		InsertColumns:       bit(5),
+		InsertRows:          bit(6),
+		InsertHyperlinks:    bit(7),
+		DeleteColumns:       bit(8),
+		DeleteRows:          bit(9),
+		SelectLockedCells:   bit(10),
+		Sort:                bit(11),
+		AutoFilter:          bit(12),
+		PivotTables:         bit(13),
+		SelectUnlockedCells: bit(14),
+	}
+}
+
+// addProtectedRange decodes a FEAT record of a protected range: a future record header,
+// isf, reserved fields, the number of refs, the size of the feature data, the refs and
+// then the flags, password verifier and title of the range.
+func (w *WorkSheet) addProtectedRange(data []byte) {
+	if len(data) < 27 || binary.LittleEndian.Uint16(data[12:]) != isfProtection {
+		return
+	}
+
+	count := int(binary.LittleEndian.Uint16(data[19:]))
+	if len(data) < 27+8*count+10 {
+		return
+	}
+
+	r := ProtectedRange{Refs: make([]CellRange, count)}
+
+	for i := range r.Refs {
+		ref := data[27+8*i:]
+		r.Refs[i] = CellRange{
+			FirstRowB: binary.LittleEndian.Uint16(ref),
+			LastRowB:  binary.LittleEndian.Uint16(ref[2:]),
+			FristColB: binary.LittleEndian.Uint16(ref[4:]),
+			LastColB:  binary.LittleEndian.Uint16(ref[6:]),
+		}
+	}
+
+	feat := data[27+8*count:]
+	r.Password = uint16(binary.LittleEndian.Uint32(feat[4:]))
+
+	if len(feat) >= 11 {
+		w.strReader.Reset(feat[10:])
+		r.Title, _ = w.wb.getString(&w.strReader, binary.LittleEndian.Uint16(feat[8:]))
+	}
+
+	w.protection.Ranges = append(w.protection.Ranges, r)
+}
+
+// verifierRecord decodes a PASSWORD or PROT4REVPASS record.
+func verifierRecord(data []byte) uint16 {
+	if len(data) < 2 {
+		return 0
+	}
+
+	return binary.LittleEndian.Uint16(data)
+}
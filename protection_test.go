@@ -0,0 +1,105 @@
+package xls
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func TestPasswordVerifier(t *testing.T) {
+	t.Parallel()
+
+	for password, want := range map[string]uint16{"": 0, "password": 0x83AF, "test": 0xCBEB} {
+		if got := PasswordVerifier(password); got != want {
+			t.Errorf("PasswordVerifier(%q) = %#04x, want %#04x", password, got, want)
+		}
+	}
+
+	// Only the first 15 characters count
+	if PasswordVerifier("0123456789abcdefgh") != PasswordVerifier("0123456789abcde") {
+		t.Error("characters past the 15th changed the verifier")
+	}
+}
+
+func TestUnprotected(t *testing.T) {
+	t.Parallel()
+
+	wb, err := Open("testdata/negatives.xls")
+	if err != nil {
+		t.Fatalf("failed to open XLS file: %v", err)
+	}
+
+	if got := wb.Protection(); got != (WorkbookProtection{}) {
+		t.Errorf("workbook protection = %+v, want none", got)
+	}
+
+	protection := wb.GetSheet(0).Protection()
+	if protection.Protected || protection.Password != 0 || !protection.CheckPassword("") {
+		t.Errorf("sheet protection = %+v, want none", protection)
+	}
+
+	want := SheetActions{EditObjects: true, EditScenarios: true, SelectLockedCells: true, SelectUnlockedCells: true}
+	if protection.Allowed != want {
+		t.Errorf("Allowed = %+v, want %+v", protection.Allowed, want)
+	}
+}
+
+// TestSheetProtection decodes the protection records of a sheet protected with a password
+// that allows sorting and has one editable range.
+func TestSheetProtection(t *testing.T) {
+	t.Parallel()
+
+	wb := &WorkBook{}
+	handleProtect(wb, u16(1))
+	handlePassword(wb, u16(PasswordVerifier("book")))
+
+	if p := wb.Protection(); !p.Structure || p.Windows || !p.CheckPassword("book") || p.CheckPassword("Book") {
+		t.Errorf("workbook protection = %+v", p)
+	}
+
+	sheet := &WorkSheet{wb: wb, protection: SheetProtection{Allowed: defaultSheetActions}}
+	sheet.parseProtection(0x12, u16(1))
+	sheet.parseProtection(0x13, u16(PasswordVerifier("secret")))
+	sheet.parseProtection(0x63, u16(1))
+
+	header := append(u16(0x867), make([]byte, 10)...)
+	header = append(header, 2, 0, 1)
+	header = binary.LittleEndian.AppendUint32(header, 0xFFFFFFFF)
+	header = binary.LittleEndian.AppendUint32(header, 0x4C00)
+	sheet.parseProtection(0x867, header)
+
+	feat := append(u16(0x868), make([]byte, 10)...)
+	feat = append(feat, 2, 0, 0, 0, 0, 0, 0, 1, 0)
+	feat = append(feat, make([]byte, 6)...)
+	feat = append(feat, u16(2)...)
+	feat = append(feat, u16(9)...)
+	feat = append(feat, u16(1)...)
+	feat = append(feat, u16(3)...)
+	feat = binary.LittleEndian.AppendUint32(feat, 0)
+	feat = binary.LittleEndian.AppendUint32(feat, uint32(PasswordVerifier("range")))
+	feat = append(feat, 6, 0, 0)
+	feat = append(feat, "Prices"...)
+	sheet.parseProtection(0x868, feat)
+
+	p := sheet.Protection()
+	if !p.Protected || !p.Objects || p.Scenarios || !p.CheckPassword("secret") || p.CheckPassword("") {
+		t.Errorf("sheet protection = %+v", p)
+	}
+
+	if want := (SheetActions{SelectLockedCells: true, Sort: true, SelectUnlockedCells: true}); p.Allowed != want {
+		t.Errorf("Allowed = %+v, want %+v", p.Allowed, want)
+	}
+
+	want := []ProtectedRange{{
+		Title:    "Prices",
+		Refs:     []CellRange{{FirstRowB: 2, LastRowB: 9, FristColB: 1, LastColB: 3}},
+		Password: PasswordVerifier("range"),
+	}}
+	if !reflect.DeepEqual(p.Ranges, want) {
+		t.Errorf("Ranges = %+v, want %+v", p.Ranges, want)
+	}
+
+	if !p.Ranges[0].CheckPassword("range") {
+		t.Error("range password rejected")
+	}
+}
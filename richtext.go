@@ -0,0 +1,57 @@
+package xls
+
+import "unicode/utf16"
+
+// Segment is a part of a string cell displayed in one font.
+type Segment struct {
+	Text string
+	// Font is the font of the segment, or nil where the cell's own font applies.
+	Font *Font
+}
+
+// Segments returns the string in column i split into its rich text runs.
+// A string without runs is a single segment without font; cells that hold no string return nil.
+func (r *Row) Segments(i int) []Segment {
+	switch ch := r.cell(i).(type) {
+	case *LabelsstCol:
+		entry := r.wb.sstEntry(ch.Sst)
+
+		return r.wb.segments(entry.str, entry.runs)
+	case *labelCol:
+		return []Segment{{Text: ch.Str}}
+	case *FormulaStringCol:
+		return []Segment{{Text: ch.RenderedValue}}
+	}
+
+	return nil
+}
+
+// segments splits str at the character offsets of its runs.
+func (wb *WorkBook) segments(str string, runs []richRun) []Segment {
+	if len(runs) == 0 {
+		return []Segment{{Text: str}}
+	}
+
+	units := utf16.Encode([]rune(str))
+	res := make([]Segment, 0, len(runs)+1)
+
+	var font *Font
+
+	start := 0
+
+	for _, run := range runs {
+		// Runs are sorted; an out of order or out of range run is clamped to the text seen so far
+		pos := min(max(int(run.start), start), len(units))
+		if pos > start {
+			res = append(res, Segment{Text: string(utf16.Decode(units[start:pos])), Font: font})
+		}
+
+		start, font = pos, wb.font(run.font)
+	}
+
+	if start < len(units) {
+		res = append(res, Segment{Text: string(utf16.Decode(units[start:])), Font: font})
+	}
+
+	return res
+}
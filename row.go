@@ -8,29 +8,25 @@ type rowInfo struct {
 	Notused  uint16
 	Notused2 uint16
 	Flags    uint32
+	record   bool // decoded from a ROW record rather than implied by a cell
 }
 
 // Row the data of one row
 type Row struct {
-	wb   *WorkBook
-	info *rowInfo
-	cols map[uint16]contentHandler
+	wb    *WorkBook
+	sheet *WorkSheet
+	info  *rowInfo
+	// cells is a dense store indexed by column - first; a record spanning
+	// several columns (MULRK, MULBLANK, hyperlink ranges) fills every slot it covers.
+	first uint16
+	cells []contentHandler
 }
 
 // Col Get the Nth Col from the Row, if has not, return nil.
 // Suggest use Has function to test it.
 func (r *Row) Col(i int) string {
-	serial := uint16(i)
-	if ch, ok := r.cols[serial]; ok {
-		strs := ch.String(r.wb)
-		return strs[0]
-	}
-
-	for _, v := range r.cols {
-		if v.FirstCol() <= serial && v.LastCol() >= serial {
-			strs := v.String(r.wb)
-			return strs[serial-v.FirstCol()]
-		}
+	if ch := r.cell(i); ch != nil {
+		return ch.cellString(r.wb, i-int(ch.FirstCol()))
 	}
 
 	return ""
@@ -39,10 +35,8 @@ func (r *Row) Col(i int) string {
 // ColExact Get the Nth Col from the Row, if has not, return nil.
 // For merged cells value is returned for first cell only
 func (r *Row) ColExact(i int) string {
-	serial := uint16(i)
-	if ch, ok := r.cols[serial]; ok {
-		strs := ch.String(r.wb)
-		return strs[0]
+	if ch := r.cell(i); ch != nil && int(ch.FirstCol()) == i {
+		return ch.cellString(r.wb, 0)
 	}
 
 	return ""
@@ -57,3 +51,92 @@ func (r *Row) LastCol() int {
 func (r *Row) FirstCol() int {
 	return int(r.info.Fcell)
 }
+
+// cell returns the record covering column i, or nil.
+func (r *Row) cell(i int) contentHandler {
+	slot := i - int(r.first)
+	if slot < 0 || slot >= len(r.cells) {
+		return nil
+	}
+
+	return r.cells[slot]
+}
+
+// setCell stores a record in every column it covers. The record always owns its first
+// column; the other columns are only taken if no record has claimed them yet.
+func (r *Row) setCell(ch contentHandler) {
+	first, last := ch.FirstCol(), ch.LastCol()
+	if last < first {
+		return
+	}
+
+	switch {
+	case len(r.cells) == 0:
+		r.first = first
+		r.cells = make([]contentHandler, int(last-first)+1)
+	case first < r.first:
+		grown := make([]contentHandler, int(r.first-first)+len(r.cells))
+		copy(grown[r.first-first:], r.cells)
+		r.cells, r.first = grown, first
+	}
+
+	if end := int(last-r.first) + 1; end > len(r.cells) {
+		if end <= cap(r.cells) {
+			r.cells = r.cells[:end]
+		} else {
+			grown := make([]contentHandler, end, max(end, 2*cap(r.cells)))
+			copy(grown, r.cells)
+			r.cells = grown
+		}
+	}
+
+	r.cells[first-r.first] = ch
+
+	for i := int(first-r.first) + 1; i <= int(last-r.first); i++ {
+		if r.cells[i] == nil {
+			r.cells[i] = ch
+		}
+	}
+}
+
+// Height returns the row height in points. Rows without a ROW record have the sheet's default height.
+func (r *Row) Height() float64 {
+	if !r.info.record {
+		return r.sheet.DefaultRowHeight()
+	}
+
+	return float64(r.info.Height&0x7FFF) / twipsPerPoint
+}
+
+// CustomHeight reports whether the height was set explicitly rather than fitted to the content.
+func (r *Row) CustomHeight() bool {
+	return r.info.Flags&0x40 != 0
+}
+
+// Hidden reports whether the row is hidden.
+func (r *Row) Hidden() bool {
+	if !r.info.record {
+		return r.sheet != nil && r.sheet.defaultRowHidden
+	}
+
+	return r.info.Flags&0x20 != 0
+}
+
+// OutlineLevel returns the row's grouping depth, 0 to 7.
+func (r *Row) OutlineLevel() int {
+	return int(r.info.Flags & 0x7)
+}
+
+// Collapsed reports whether the group ending next to the row is collapsed.
+func (r *Row) Collapsed() bool {
+	return r.info.Flags&0x10 != 0
+}
+
+// XF returns the index of the row's default cell format, and false if the row has none.
+func (r *Row) XF() (uint16, bool) {
+	if r.info.Flags&0x80 == 0 {
+		return 0, false
+	}
+
+	return uint16(r.info.Flags >> 16 & 0xFFF), true
+}
@@ -0,0 +1,103 @@
+package xls
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+)
+
+// TestRowDenseCells checks column lookup in the dense row store: spans fill every column
+// they cover, a record always owns its first column, and the store grows in both directions.
+func TestRowDenseCells(t *testing.T) {
+	t.Parallel()
+
+	row := &Row{info: new(rowInfo), wb: new(WorkBook)}
+
+	row.setCell(&MulBlankCol{Col: Col{FirstColB: 4}, Xfs: make([]uint16, 3), LastColB: 6})
+	row.setCell(&labelCol{BlankCol: BlankCol{Col: Col{FirstColB: 5}}, Str: "five"})
+	row.setCell(&labelCol{BlankCol: BlankCol{Col: Col{FirstColB: 1}}, Str: "one"})
+	row.setCell(&HyperLink{CellRange: CellRange{FristColB: 8, LastColB: 10}, ExtendedFilePath: "link"})
+
+	want := map[int]string{0: "", 1: "one", 4: "", 5: "five", 6: "", 8: "link", 9: "link", 10: "link", 11: ""}
+	for col, exp := range want {
+		if got := row.Col(col); got != exp {
+			t.Errorf("Col(%d): got %q, want %q", col, got, exp)
+		}
+	}
+
+	if got := row.ColExact(9); got != "" {
+		t.Errorf("ColExact(9): got %q, want empty for a column inside a span", got)
+	}
+
+	if got := row.ColExact(8); got != "link" {
+		t.Errorf("ColExact(8): got %q, want %q", got, "link")
+	}
+}
+
+// TestRowSparseColumns parses rows with a BLANK record in the first column and one at column
+// 65535: the second lies past the BIFF8 limit and must not stretch the dense store to 64K slots.
+func TestRowSparseColumns(t *testing.T) {
+	t.Parallel()
+
+	blank := func(row, col uint16) []byte {
+		data := binary.LittleEndian.AppendUint16(nil, row)
+		data = binary.LittleEndian.AppendUint16(data, col)
+
+		return biffRecord(0x201, data, []byte{15, 0})
+	}
+
+	records := [][]byte{biffRecord(0x809, []byte{0, 6, 0x10, 0}, make([]byte, 12))}
+	for row := uint16(0); row < 100; row++ {
+		records = append(records, blank(row, 0), blank(row, 0xFFFF))
+	}
+
+	records = append(records, biffRecord(0x0A))
+
+	wb := &WorkBook{rs: bytes.NewReader(bytes.Join(records, nil))}
+	sheet := &WorkSheet{wb: wb, bs: &boundsheet{}, Name: "Sparse"}
+	wb.sheets = []*WorkSheet{sheet}
+
+	if err := sheet.parse(context.Background()); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	for i := 0; i < 100; i++ {
+		row := sheet.Row(i)
+		if row == nil || row.cell(0) == nil {
+			t.Fatalf("row %d: the cell in the first column is missing", i)
+		}
+
+		if len(row.cells) > maxColIndex+1 {
+			t.Fatalf("row %d: %d slots allocated", i, len(row.cells))
+		}
+	}
+
+	if sheet.cells != 100 {
+		t.Errorf("counted %d cells, want 100", sheet.cells)
+	}
+}
+
+// TestRowMetadata decodes the flags of a ROW record and falls back to DEFAULTROWHEIGHT for rows without one.
+func TestRowMetadata(t *testing.T) {
+	t.Parallel()
+
+	sheet := &WorkSheet{wb: new(WorkBook), rows: make(map[uint16]*Row)}
+	sheet.setDefaultRowHeight([]byte{0x02, 0, 0x2C, 0x01})
+
+	// row 3, 30pt, outline level 2, collapsed, hidden, custom height, XF 21
+	sheet.addRow(decodeRowInfo([]byte{3, 0, 0, 0, 1, 0, 0x58, 0x02, 0, 0, 0, 0, 0xF2, 0, 21, 0}))
+	sheet.addContent(5, &BlankCol{Col: Col{RowB: 5}})
+
+	row := sheet.Row(3)
+	xf, ok := row.XF()
+
+	if row.Height() != 30 || !row.Hidden() || !row.CustomHeight() || !row.Collapsed() || row.OutlineLevel() != 2 || !ok || xf != 21 {
+		t.Errorf("row 3: height %v hidden %v custom %v collapsed %v level %d xf %d, %v",
+			row.Height(), row.Hidden(), row.CustomHeight(), row.Collapsed(), row.OutlineLevel(), xf, ok)
+	}
+
+	if row := sheet.Row(5); row.Height() != 15 || !row.Hidden() {
+		t.Errorf("row 5: height %v hidden %v, want the default 15 and hidden", row.Height(), row.Hidden())
+	}
+}
@@ -0,0 +1,244 @@
+//nolint:mnd
+package xls
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+)
+
+// errNoRowIndex is returned internally when a sheet has no usable INDEX/DBCELL chain.
+var errNoRowIndex = errors.New("xls: no row index")
+
+// maxRowIndex is the largest row number a BIFF8 sheet can hold.
+const maxRowIndex = 0xFFFF
+
+// ReadRows returns the rows from..to (inclusive, 0-based) of the sheet, with nil entries for
+// rows that have no content. If the sheet has not been parsed yet, only the 32-row blocks that
+// overlap the range are decoded, located through the INDEX and DBCELL records, along with the
+// hyperlinks recorded after the blocks; sheets without them are parsed completely first.
+func (w *WorkSheet) ReadRows(from, to int) ([]*Row, error) {
+	if from < 0 || to < from || from > maxRowIndex {
+		return nil, nil
+	}
+
+	to = min(to, maxRowIndex)
+
+	if !w.parsed {
+		rows, err := w.readIndexedRows(from, to)
+		if !errors.Is(err, errNoRowIndex) {
+			return rows, err
+		}
+
+		if err := w.wb.prepareSheet(context.Background(), w); err != nil {
+			return nil, err
+		}
+	}
+
+	rows := make([]*Row, to-from+1)
+	for i := range rows {
+		rows[i] = w.Row(from + i)
+	}
+
+	return rows, nil
+}
+
+// readIndex scans the sheet header for the INDEX record and returns the stream offsets
+// of the DBCELL records it lists. The other header records, such as the default row height,
+// are decoded into header.
+func (w *WorkSheet) readIndex(header *WorkSheet) ([]uint32, error) {
+	if w.wb.Is5ver {
+		return nil, errNoRowIndex
+	}
+
+	records, err := acquireRecordReader(w.wb.rs, int64(w.bs.Filepos))
+	if err != nil {
+		return nil, err
+	}
+	defer records.release()
+
+	var offsets []uint32
+
+	for first := true; ; first = false {
+		b, data, _, err := records.next()
+		if err != nil {
+			return nil, errNoRowIndex
+		}
+
+		if b.ID == 0x809 && !first { // BOF of an embedded chart: the sheet had no rows
+			return nil, errNoRowIndex
+		}
+
+		switch b.ID {
+		case 0x20B: // INDEX
+			if len(data) < 16 {
+				return nil, errNoRowIndex
+			}
+
+			offsets = make([]uint32, (len(data)-16)/4)
+			for i := range offsets {
+				offsets[i] = binary.LittleEndian.Uint32(data[16+4*i:])
+			}
+		case 0x208, 0xa: // ROW or EOF: the header ends, and the index would have been before them
+			if offsets == nil {
+				return nil, errNoRowIndex
+			}
+
+			return offsets, nil
+		default:
+			header.parseBof(b, data, nil)
+		}
+	}
+}
+
+// readIndexedRows decodes the row blocks overlapping from..to into a scratch sheet.
+// Each DBCELL record points back to the first ROW record of its block, and the block's
+// ROW records come first, so blocks outside the range are skipped before any cell is decoded.
+func (w *WorkSheet) readIndexedRows(from, to int) ([]*Row, error) {
+	scratch := &WorkSheet{bs: w.bs, wb: w.wb, Name: w.Name, Kind: w.Kind, rows: make(map[uint16]*Row)}
+
+	offsets, err := w.readIndex(scratch)
+	if err != nil {
+		return nil, err
+	}
+
+	tracker := newRecordTracker(context.Background(), w.wb, w.Name)
+
+	for _, dbCell := range offsets {
+		first, err := w.readIndexedBlock(scratch, tracker, int64(dbCell), from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		if first > to {
+			break
+		}
+	}
+
+	if len(offsets) > 0 {
+		if err := w.readIndexedTail(scratch, tracker, int64(offsets[len(offsets)-1]), from, to); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tracker.finish(); err != nil {
+		return nil, err
+	}
+
+	if w.wb.opts.SkipFilteredRows {
+		scratch.dropFilteredRows()
+	}
+
+	rows := make([]*Row, to-from+1)
+	for i := range rows {
+		if row := scratch.rows[uint16(from+i)]; row != nil {
+			row.wb = w.wb
+			rows[i] = row
+		}
+	}
+
+	return rows, nil
+}
+
+// readIndexedBlock reads the row block ending at the DBCELL record at the given offset into
+// scratch, decoding its cells only if the block's rows overlap from..to. It returns the first
+// row of the block.
+func (w *WorkSheet) readIndexedBlock(scratch *WorkSheet, tracker *recordTracker, dbCell int64, from, to int) (int, error) {
+	start, err := w.blockStart(dbCell)
+	if err != nil {
+		return 0, err
+	}
+
+	records, err := acquireRecordReader(w.wb.rs, start)
+	if err != nil {
+		return 0, errNoRowIndex
+	}
+	defer records.release()
+
+	first, last := maxRowIndex+1, -1
+	var colPre interface{}
+
+	for records.pos < dbCell {
+		b, data, _, err := records.next()
+		if err != nil {
+			return 0, errNoRowIndex
+		}
+
+		if err := tracker.next(b.Size); err != nil {
+			return 0, err
+		}
+
+		if b.ID == 0x208 { // ROW: widen the block's row range
+			if len(data) < 16 {
+				return 0, errNoRowIndex
+			}
+
+			info := decodeRowInfo(data)
+			scratch.addRow(info)
+			first, last = min(first, int(info.Index)), max(last, int(info.Index))
+
+			continue
+		}
+
+		if last < from || first > to {
+			break
+		}
+
+		colPre = scratch.parseBof(b, data, colPre)
+	}
+
+	return first, nil
+}
+
+// readIndexedTail decodes the HYPERLINK records that follow the row blocks, from the last
+// DBCELL record to the end of the sheet, for links on rows in from..to. The substreams of
+// embedded charts are skipped.
+func (w *WorkSheet) readIndexedTail(scratch *WorkSheet, tracker *recordTracker, dbCell int64, from, to int) error {
+	records, err := acquireRecordReader(w.wb.rs, dbCell)
+	if err != nil {
+		return errNoRowIndex
+	}
+	defer records.release()
+
+	for depth := 1; depth > 0; {
+		b, data, _, err := records.next()
+		if err != nil {
+			return nil
+		}
+
+		if err := tracker.next(b.Size); err != nil {
+			return err
+		}
+
+		switch b.ID {
+		case 0x809: // BOF
+			depth++
+		case 0xa: // EOF
+			depth--
+		case 0x1B8: // HYPERLINK: the first and last rows come first
+			if depth == 1 && len(data) >= 4 &&
+				int(binary.LittleEndian.Uint16(data)) <= to && int(binary.LittleEndian.Uint16(data[2:])) >= from {
+				scratch.parseBof(b, data, nil)
+			}
+		}
+	}
+
+	return nil
+}
+
+// blockStart reads the DBCELL record at the given offset and returns the stream offset
+// of the first ROW record of its block.
+func (w *WorkSheet) blockStart(dbCell int64) (int64, error) {
+	records, err := acquireRecordReader(w.wb.rs, dbCell)
+	if err != nil {
+		return 0, errNoRowIndex
+	}
+	defer records.release()
+
+	b, data, _, err := records.next()
+	if err != nil || b.ID != 0xD7 || len(data) < 4 || int64(binary.LittleEndian.Uint32(data)) > dbCell {
+		return 0, errNoRowIndex
+	}
+
+	return dbCell - int64(binary.LittleEndian.Uint32(data)), nil
+}
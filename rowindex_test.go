@@ -0,0 +1,158 @@
+package xls
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+)
+
+// TestReadRows checks that rows decoded through the INDEX/DBCELL records match a full parse,
+// and that sheets without an index fall back to parsing everything.
+func TestReadRows(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		filePath string
+		indexed  bool
+	}{
+		{"testdata/issue47.xls", true},
+		{"testdata/bigtable.xls", false},
+	}
+
+	for _, tt := range tests {
+		filePath, indexed := tt.filePath, tt.indexed
+
+		t.Run(filePath, func(t *testing.T) {
+			t.Parallel()
+
+			full, err := Open(filePath)
+			if err != nil {
+				t.Fatalf("failed to open XLS file: %v", err)
+			}
+
+			lazy, err := Open(filePath)
+			if err != nil {
+				t.Fatalf("failed to open XLS file: %v", err)
+			}
+
+			sheet := full.GetSheet(0)
+			from, to := 90, 160
+
+			rows, err := lazy.Sheet(0).ReadRows(from, to)
+			if err != nil {
+				t.Fatalf("ReadRows failed: %v", err)
+			}
+
+			if parsed := lazy.Sheet(0).parsed; parsed == indexed {
+				t.Errorf("sheet parsed completely: %v, want %v", parsed, !indexed)
+			}
+
+			if len(rows) != to-from+1 {
+				t.Fatalf("got %d rows, want %d", len(rows), to-from+1)
+			}
+
+			for i, row := range rows {
+				want := sheet.Row(from + i)
+				if (row == nil) != (want == nil) {
+					t.Fatalf("row %d: got %v, want %v", from+i, row, want)
+				}
+
+				if row == nil {
+					continue
+				}
+
+				if row.Height() != want.Height() || row.Hidden() != want.Hidden() {
+					t.Errorf("row %d: height %v hidden %v, want height %v hidden %v",
+						from+i, row.Height(), row.Hidden(), want.Height(), want.Hidden())
+				}
+
+				for col := want.FirstCol(); col <= want.LastCol(); col++ {
+					if got, exp := row.Col(col), want.Col(col); got != exp {
+						t.Errorf("row %d, col %d: got %q, want %q", from+i, col, got, exp)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestReadRowsHyperlinks reads rows of an indexed sheet whose HYPERLINK records come after
+// the row blocks, and checks that the links show as in a full parse.
+func TestReadRowsHyperlinks(t *testing.T) {
+	t.Parallel()
+
+	utf16z := func(s string) []byte {
+		res := binary.LittleEndian.AppendUint32(nil, uint32(len(s)+1))
+		for _, c := range s + "\x00" {
+			res = binary.LittleEndian.AppendUint16(res, uint16(c))
+		}
+
+		return res
+	}
+
+	label := func(row, col uint16, s string) []byte {
+		return biffRecord(0x204, u16(row, col, 15, uint16(len(s))), []byte{0}, []byte(s))
+	}
+
+	// a URL moniker with a description
+	url := utf16z("https://example.com")[4:]
+	link := bytes.Join([][]byte{
+		u16(1, 1, 1, 1), make([]byte, 20), {0x17, 0, 0, 0}, utf16z("Docs"),
+		{0xE0, 0xC9, 0xEA, 0x79, 0xF9, 0xBA, 0xCE, 0x11, 0x8C, 0x82, 0x00, 0xAA, 0x00, 0x4B, 0xA9, 0x0B},
+		binary.LittleEndian.AppendUint32(nil, uint32(len(url))), url,
+	}, nil)
+
+	bof := biffRecord(0x809, u16(0x600, 0x10), make([]byte, 12))
+	rows := bytes.Join([][]byte{
+		biffRecord(0x208, u16(0, 0, 2, 255, 0, 0, 0x100, 0)),
+		biffRecord(0x208, u16(1, 0, 2, 255, 0, 0, 0x100, 0)),
+		label(0, 0, "a"), label(0, 1, "b"), label(1, 0, "c"), label(1, 1, "d"),
+	}, nil)
+
+	rowsStart := len(bof) + 4 + 20
+	dbCell := rowsStart + len(rows)
+
+	stream := bytes.Join([][]byte{
+		bof,
+		biffRecord(0x20B, make([]byte, 8), binary.LittleEndian.AppendUint32(nil, 2), make([]byte, 4),
+			binary.LittleEndian.AppendUint32(nil, uint32(dbCell))),
+		rows,
+		biffRecord(0xD7, binary.LittleEndian.AppendUint32(nil, uint32(dbCell-rowsStart))),
+		biffRecord(0x1B8, link),
+		biffRecord(0x0A),
+	}, nil)
+
+	open := func() *WorkSheet {
+		wb := &WorkBook{rs: bytes.NewReader(stream)}
+		sheet := &WorkSheet{wb: wb, bs: &boundsheet{}, Name: "Links"}
+		wb.sheets = []*WorkSheet{sheet}
+
+		return sheet
+	}
+
+	full := open()
+	if err := full.parse(context.Background()); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	lazy := open()
+
+	got, err := lazy.ReadRows(1, 1)
+	if err != nil {
+		t.Fatalf("ReadRows failed: %v", err)
+	}
+
+	if lazy.parsed {
+		t.Fatalf("sheet parsed completely, want the indexed path")
+	}
+
+	want := full.Row(1).Col(1)
+	if want != "Docs(https://example.com)" {
+		t.Fatalf("full parse: link cell %q", want)
+	}
+
+	if link := got[0].Col(1); link != want {
+		t.Errorf("ReadRows: link cell %q, want %q", link, want)
+	}
+}
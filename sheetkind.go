@@ -0,0 +1,82 @@
+//nolint:mnd
+package xls
+
+import (
+	"encoding/binary"
+	"image/color"
+)
+
+// SheetKind is the type of a sheet, from its BOUNDSHEET record.
+type SheetKind byte
+
+const (
+	// SheetKindWorksheet is a worksheet or a dialog sheet.
+	SheetKindWorksheet SheetKind = 0
+	// SheetKindMacro is an Excel 4.0 macro sheet. It holds cells like a worksheet.
+	SheetKindMacro SheetKind = 1
+	// SheetKindChart is a chart sheet. It has no cells.
+	SheetKindChart SheetKind = 2
+	// SheetKindVBModule is a Visual Basic module sheet. It has no cells.
+	SheetKindVBModule SheetKind = 6
+)
+
+func (k SheetKind) String() string {
+	switch k {
+	case SheetKindWorksheet:
+		return "worksheet"
+	case SheetKindMacro:
+		return "macro"
+	case SheetKindChart:
+		return "chart"
+	case SheetKindVBModule:
+		return "vbmodule"
+	}
+
+	return "unknown"
+}
+
+// hasCells reports whether sheets of this kind hold cells.
+func (k SheetKind) hasCells() bool {
+	return k == SheetKindWorksheet || k == SheetKindMacro
+}
+
+// isCellRecord reports whether a record of the sheet substream holds cells or rows.
+// Chart substreams reuse some of these records for their cached series values.
+func isCellRecord(id uint16) bool {
+	switch id {
+	case 0x208, 0xBD, 0xBE, 0x203, 0x06, 0x207, 0x27E, 0xFD, 0x204, 0x201, 0x1B8:
+		return true
+	}
+
+	return false
+}
+
+// TabColor returns the color of the sheet's tab, and false if it has the default color.
+func (w *WorkSheet) TabColor() (color.RGBA, bool) {
+	if w.tabRGB != nil {
+		return *w.tabRGB, true
+	}
+
+	if w.tabColor == 0 || w.tabColor == 0x7F {
+		return color.RGBA{}, false
+	}
+
+	return w.wb.PaletteColor(w.tabColor)
+}
+
+// setSheetExt decodes a SHEETEXT record: a future record header, the size of the
+// structure, the tab color as a palette index and, in the longer form, the tab color
+// as an RGB value or theme color.
+func (w *WorkSheet) setSheetExt(data []byte) {
+	if len(data) < 20 {
+		return
+	}
+
+	w.tabColor = int(binary.LittleEndian.Uint32(data[16:]) & 0x7F)
+	w.tabRGB = nil
+
+	// SheetExtOptional: tab color, flags, then a CFColor of type RGB (2)
+	if binary.LittleEndian.Uint32(data[12:]) >= 0x28 && len(data) >= 36 && binary.LittleEndian.Uint32(data[24:]) == 2 {
+		w.tabRGB = &color.RGBA{R: data[28], G: data[29], B: data[30], A: 0xFF}
+	}
+}
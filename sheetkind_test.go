@@ -0,0 +1,64 @@
+package xls
+
+import (
+	"image/color"
+	"testing"
+)
+
+// TestChartSheetCells checks that the cached series values of a chart substream do not become cells.
+func TestChartSheetCells(t *testing.T) {
+	t.Parallel()
+
+	number := []byte{1, 0, 2, 0, 15, 0, 0, 0, 0, 0, 0, 0, 0xF0, 0x3F}
+
+	for _, kind := range []SheetKind{SheetKindWorksheet, SheetKindChart} {
+		sheet := &WorkSheet{wb: new(WorkBook), rows: make(map[uint16]*Row), Kind: kind}
+		sheet.parseBof(bof{ID: 0x203}, number, nil)
+
+		if got, want := len(sheet.rows), map[SheetKind]int{SheetKindWorksheet: 1, SheetKindChart: 0}[kind]; got != want {
+			t.Errorf("%v: got %d rows, want %d", kind, got, want)
+		}
+	}
+}
+
+func TestTabColor(t *testing.T) {
+	t.Parallel()
+
+	wb := new(WorkBook)
+	sheet := &WorkSheet{wb: wb}
+
+	if _, ok := sheet.TabColor(); ok {
+		t.Error("sheet without SHEETEXT has a tab color")
+	}
+
+	sheetExt := []byte{0x62, 0x08, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x14, 0, 0, 0, 10, 0, 0, 0}
+	sheet.setSheetExt(sheetExt)
+
+	if got, ok := sheet.TabColor(); !ok || got != (color.RGBA{R: 0xFF, A: 0xFF}) {
+		t.Errorf("TabColor() = %v, %v; want red", got, ok)
+	}
+
+	// PALETTE replacing index 8 and 9, then 10
+	handlePalette(wb, []byte{3, 0, 0, 0, 0, 0, 0xFF, 0xFF, 0xFF, 0, 0x12, 0x34, 0x56, 0})
+
+	if got, ok := sheet.TabColor(); !ok || got != (color.RGBA{R: 0x12, G: 0x34, B: 0x56, A: 0xFF}) {
+		t.Errorf("TabColor() with palette = %v, %v; want #123456", got, ok)
+	}
+
+	// PALETTE replacing index 8 leaves the fixed black of index 0 alone
+	handlePalette(wb, []byte{1, 0, 0x12, 0x34, 0x56, 0})
+
+	for idx, want := range map[int]color.RGBA{0: {A: 0xFF}, 8: {R: 0x12, G: 0x34, B: 0x56, A: 0xFF}, 9: {R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}} {
+		if got, ok := wb.PaletteColor(idx); !ok || got != want {
+			t.Errorf("PaletteColor(%d) with palette = %v, %v; want %v", idx, got, ok, want)
+		}
+	}
+
+	sheetExt[12] = 0x28
+	sheetExt = append(sheetExt, 0, 0, 0, 0, 2, 0, 0, 0, 0xAB, 0xCD, 0xEF, 0xFF, 0, 0, 0, 0, 0, 0, 0, 0)
+	sheet.setSheetExt(sheetExt)
+
+	if got, ok := sheet.TabColor(); !ok || got != (color.RGBA{R: 0xAB, G: 0xCD, B: 0xEF, A: 0xFF}) {
+		t.Errorf("TabColor() with RGB = %v, %v; want #ABCDEF", got, ok)
+	}
+}
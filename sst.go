@@ -1,6 +1,500 @@
+//nolint:mnd
 package xls
 
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"unicode/utf16"
+)
+
 type SstInfo struct {
 	Total uint32
 	Count uint32
 }
+
+// errSSTTruncated is returned when a shared string runs past the last SST/CONTINUE record.
+var errSSTTruncated = errors.New("xls: truncated shared string table")
+
+// sstSegment is the payload of the SST record or of one of the CONTINUE records following it.
+type sstSegment struct {
+	pos  int64  // stream offset of the payload
+	size int    // payload size
+	data []byte // payload, only kept until the table has been decoded or indexed
+}
+
+// sstTable is the workbook's shared string table.
+//
+// By default every string is decoded into WorkBook.sst once the workbook globals have been read.
+// With Options.LazySST, only the record positions and the EXTSST buckets are kept, and strings
+// are decoded from the stream when a cell asks for them.
+type sstTable struct {
+	count      int
+	segments   []sstSegment
+	open       bool // the last record was the SST or one of its CONTINUE records
+	bucketSize int
+	buckets    []int64 // stream offsets of the first string of every EXTSST bucket
+	lazy       bool
+	cache      map[uint32]sstEntry
+	runs       map[uint32][]richRun // rich text runs of the eagerly decoded strings that have any
+	phonetics  map[uint32]*Phonetic // phonetic readings of the eagerly decoded strings that have any
+}
+
+// sstEntry is one decoded shared string with its formatting.
+type sstEntry struct {
+	str      string
+	runs     []richRun
+	phonetic *Phonetic
+}
+
+// richRun marks where a font starts inside a rich text string.
+type richRun struct {
+	start uint16 // offset of the first character, in UTF-16 code units
+	font  uint16 // FONT record index, as stored in the file
+}
+
+// addSST starts the table from an SST record. The payload is copied.
+func (wb *WorkBook) addSST(data []byte, pos int64) {
+	info := new(SstInfo)
+	if len(data) >= 8 {
+		info.Total = binary.LittleEndian.Uint32(data)
+		info.Count = binary.LittleEndian.Uint32(data[4:])
+	}
+
+	if wb.checkLimit("MaxSSTStrings", int64(info.Count), int64(wb.opts.Limits.MaxSSTStrings)) != nil {
+		return
+	}
+
+	wb.sstTable = &sstTable{count: int(info.Count), open: true}
+	wb.sstTable.segments = append(wb.sstTable.segments, sstSegment{pos: pos, size: len(data), data: bytes.Clone(data)})
+}
+
+// addSSTContinue appends a copy of a CONTINUE record if it belongs to the SST.
+func (wb *WorkBook) addSSTContinue(data []byte, pos int64) {
+	if t := wb.sstTable; t != nil && t.open {
+		t.segments = append(t.segments, sstSegment{pos: pos, size: len(data), data: bytes.Clone(data)})
+	}
+}
+
+// addExtSST reads the EXTSST record, which lists the stream offset of every
+// bucketSize-th string so that a string can be found without decoding its predecessors.
+func (wb *WorkBook) addExtSST(data []byte) {
+	t := wb.sstTable
+	if t == nil || len(data) < 2 {
+		return
+	}
+
+	t.bucketSize = int(binary.LittleEndian.Uint16(data))
+	t.buckets = make([]int64, 0, (len(data)-2)/8)
+
+	for i := 2; i+8 <= len(data); i += 8 {
+		t.buckets = append(t.buckets, int64(binary.LittleEndian.Uint32(data[i:])))
+	}
+}
+
+// finishSST is called at the end of the workbook globals. It either decodes all strings,
+// or, in lazy mode with a consistent EXTSST, drops the record payloads and keeps only their positions.
+func (wb *WorkBook) finishSST() {
+	t := wb.sstTable
+	if t == nil {
+		return
+	}
+
+	t.open = false
+
+	if wb.opts.LazySST && t.indexed(wb) {
+		t.lazy = true
+
+		if wb.opts.CacheSST {
+			t.cache = make(map[uint32]sstEntry)
+		}
+	} else {
+		// The count is untrusted: every string needs at least 3 bytes, so never
+		// reserve more entries than the records could hold, and grow from there.
+		size := 0
+		for _, seg := range t.segments {
+			size += seg.size
+		}
+
+		wb.sst = make([]string, 0, min(t.count, size/3))
+		reader := newSSTReader(wb, t.segments, 0, 8)
+
+		for len(wb.sst) < t.count {
+			entry, err := reader.readString()
+			if err != nil {
+				break
+			}
+
+			if entry.runs != nil {
+				if t.runs == nil {
+					t.runs = make(map[uint32][]richRun)
+				}
+
+				t.runs[uint32(len(wb.sst))] = entry.runs
+			}
+
+			if entry.phonetic != nil {
+				if t.phonetics == nil {
+					t.phonetics = make(map[uint32]*Phonetic)
+				}
+
+				t.phonetics[uint32(len(wb.sst))] = entry.phonetic
+			}
+
+			wb.sst = append(wb.sst, entry.str)
+		}
+	}
+
+	for i := range t.segments {
+		t.segments[i].data = nil
+	}
+}
+
+// indexed reports whether the EXTSST buckets cover the table and each points at the first
+// byte of its string. A stale or corrupt EXTSST would otherwise make lazy lookups decode from
+// the middle of a string, so the strings are skipped through once, without decoding them,
+// to check every bucket.
+func (t *sstTable) indexed(wb *WorkBook) bool {
+	if t.bucketSize == 0 || len(t.buckets)*t.bucketSize < t.count {
+		return false
+	}
+
+	reader := newSSTReader(wb, t.segments, 0, 8)
+
+	for i := 0; i < t.count; i++ {
+		if i%t.bucketSize == 0 && reader.pos() != t.buckets[i/t.bucketSize] {
+			return false
+		}
+
+		if reader.skipString() != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// locate returns the segment containing the given stream offset and the offset inside it.
+func (t *sstTable) locate(pos int64) (int, int, bool) {
+	for i, seg := range t.segments {
+		if pos >= seg.pos && pos < seg.pos+int64(seg.size) {
+			return i, int(pos - seg.pos), true
+		}
+	}
+
+	return 0, 0, false
+}
+
+// sstString returns the shared string with the given index, or an empty string if there is none.
+func (wb *WorkBook) sstString(idx uint32) string {
+	return wb.sstEntry(idx).str
+}
+
+// sstEntry returns the shared string with the given index and its formatting.
+func (wb *WorkBook) sstEntry(idx uint32) sstEntry {
+	t := wb.sstTable
+	if t == nil || !t.lazy {
+		if int64(idx) >= int64(len(wb.sst)) {
+			return sstEntry{}
+		}
+
+		entry := sstEntry{str: wb.sst[idx]}
+		if t != nil {
+			entry.runs = t.runs[idx]
+			entry.phonetic = t.phonetics[idx]
+		}
+
+		return entry
+	}
+
+	if int64(idx) >= int64(t.count) {
+		return sstEntry{}
+	}
+
+	if entry, ok := t.cache[idx]; ok {
+		return entry
+	}
+
+	bucket := int(idx) / t.bucketSize
+	seg, offset, _ := t.locate(t.buckets[bucket])
+	reader := newSSTReader(wb, t.segments, seg, offset)
+
+	var entry sstEntry
+
+	for i := uint32(bucket * t.bucketSize); i <= idx; i++ {
+		var err error
+		if entry, err = reader.readString(); err != nil {
+			return sstEntry{}
+		}
+
+		if t.cache != nil {
+			t.cache[i] = entry
+		}
+	}
+
+	return entry
+}
+
+// sstReader decodes strings from a sequence of SST/CONTINUE payloads, following strings
+// across record boundaries. Payloads that were dropped are read back from the workbook stream.
+type sstReader struct {
+	wb       *WorkBook
+	segments []sstSegment
+	seg      int
+	data     []byte
+	off      int
+}
+
+func newSSTReader(wb *WorkBook, segments []sstSegment, seg, off int) *sstReader {
+	r := &sstReader{wb: wb, segments: segments, seg: seg - 1}
+	if r.advance() == nil {
+		r.off = min(off, len(r.data))
+	}
+
+	return r
+}
+
+// advance moves to the start of the next segment.
+func (r *sstReader) advance() error {
+	r.seg++
+	if r.seg >= len(r.segments) {
+		return errSSTTruncated
+	}
+
+	r.off = 0
+	r.data = r.segments[r.seg].data
+
+	if r.data == nil {
+		seg := r.segments[r.seg]
+		r.data = make([]byte, seg.size)
+
+		if _, err := r.wb.rs.Seek(seg.pos, io.SeekStart); err != nil {
+			return err
+		}
+
+		if _, err := io.ReadFull(r.wb.rs, r.data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bytes returns the next n raw bytes, which may span segments.
+func (r *sstReader) bytes(n int) ([]byte, error) {
+	if r.off+n <= len(r.data) {
+		r.off += n
+
+		return r.data[r.off-n : r.off], nil
+	}
+
+	// n may come from a corrupt length, so grow with the data actually present
+	res := make([]byte, 0, min(n, len(r.data)-r.off+maxRecordSize))
+
+	for len(res) < n {
+		if r.off == len(r.data) {
+			if err := r.advance(); err != nil {
+				return nil, err
+			}
+		}
+
+		take := min(n-len(res), len(r.data)-r.off)
+		res = append(res, r.data[r.off:r.off+take]...)
+		r.off += take
+	}
+
+	return res, nil
+}
+
+// skip moves past the next n raw bytes without copying them.
+func (r *sstReader) skip(n int64) error {
+	for n > int64(len(r.data)-r.off) {
+		n -= int64(len(r.data) - r.off)
+		if err := r.advance(); err != nil {
+			return err
+		}
+	}
+
+	r.off += int(n)
+
+	return nil
+}
+
+// pos returns the stream offset of the next byte to read. At the end of a segment, that is
+// the start of the next one, where a string following a record boundary begins.
+func (r *sstReader) pos() int64 {
+	if r.off == len(r.data) && r.seg+1 < len(r.segments) {
+		return r.segments[r.seg+1].pos
+	}
+
+	return r.segments[r.seg].pos + int64(r.off)
+}
+
+// skipString moves past one string like readString, without decoding it.
+func (r *sstReader) skipString() error {
+	header, err := r.bytes(3)
+	if err != nil {
+		return err
+	}
+
+	size := int(binary.LittleEndian.Uint16(header))
+	flag := header[2]
+
+	var extra int64
+
+	if flag&0x8 != 0 {
+		bts, err := r.bytes(2)
+		if err != nil {
+			return err
+		}
+
+		extra += 4 * int64(binary.LittleEndian.Uint16(bts))
+	}
+
+	if flag&0x4 != 0 {
+		bts, err := r.bytes(4)
+		if err != nil {
+			return err
+		}
+
+		extra += int64(binary.LittleEndian.Uint32(bts))
+	}
+
+	for size > 0 {
+		if r.off == len(r.data) {
+			if err := r.advance(); err != nil {
+				return err
+			}
+
+			bts, err := r.bytes(1)
+			if err != nil {
+				return err
+			}
+
+			flag = bts[0]
+		}
+
+		width := 1
+		if flag&0x1 != 0 {
+			width = 2
+		}
+
+		take := min(size, (len(r.data)-r.off)/width)
+		if take == 0 {
+			return errSSTTruncated
+		}
+
+		r.off += width * take
+		size -= take
+	}
+
+	return r.skip(extra)
+}
+
+// readString decodes one XLUnicodeRichExtendedString. When the character data
+// continues in the next record, that record starts with a fresh flags byte
+// telling whether the remaining characters are compressed.
+func (r *sstReader) readString() (sstEntry, error) {
+	var entry sstEntry
+
+	header, err := r.bytes(3)
+	if err != nil {
+		return entry, err
+	}
+
+	size := binary.LittleEndian.Uint16(header)
+	flag := header[2]
+
+	if err := r.wb.checkLimit("MaxStringLength", int64(size), int64(r.wb.opts.Limits.MaxStringLength)); err != nil {
+		return entry, err
+	}
+
+	var richtextNum uint16
+	var phoneticSize uint32
+
+	if flag&0x8 != 0 {
+		bts, err := r.bytes(2)
+		if err != nil {
+			return entry, err
+		}
+
+		richtextNum = binary.LittleEndian.Uint16(bts)
+	}
+
+	if flag&0x4 != 0 {
+		bts, err := r.bytes(4)
+		if err != nil {
+			return entry, err
+		}
+
+		phoneticSize = binary.LittleEndian.Uint32(bts)
+	}
+
+	chars := make([]uint16, 0, size)
+
+	for len(chars) < int(size) {
+		if r.off == len(r.data) {
+			if err := r.advance(); err != nil {
+				return entry, err
+			}
+
+			bts, err := r.bytes(1)
+			if err != nil {
+				return entry, err
+			}
+
+			flag = bts[0]
+		}
+
+		remaining := int(size) - len(chars)
+
+		if flag&0x1 != 0 {
+			take := min(remaining, (len(r.data)-r.off)/2)
+			for i := 0; i < take; i++ {
+				chars = append(chars, binary.LittleEndian.Uint16(r.data[r.off+2*i:]))
+			}
+
+			r.off += 2 * take
+
+			if take == 0 {
+				return entry, errSSTTruncated
+			}
+		} else {
+			take := min(remaining, len(r.data)-r.off)
+			for _, c := range r.data[r.off : r.off+take] {
+				chars = append(chars, uint16(c))
+			}
+
+			r.off += take
+		}
+	}
+
+	entry.str = string(utf16.Decode(chars))
+
+	if richtextNum > 0 {
+		bts, err := r.bytes(4 * int(richtextNum))
+		if err != nil {
+			return entry, err
+		}
+
+		entry.runs = make([]richRun, richtextNum)
+		for i := range entry.runs {
+			entry.runs[i] = richRun{
+				start: binary.LittleEndian.Uint16(bts[4*i:]),
+				font:  binary.LittleEndian.Uint16(bts[4*i+2:]),
+			}
+		}
+	}
+
+	if phoneticSize > 0 {
+		bts, err := r.bytes(int(phoneticSize))
+		if err != nil {
+			return entry, err
+		}
+
+		entry.phonetic = r.wb.parseExtRst(bts, chars)
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,197 @@
+package xls
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+)
+
+// TestLazySST checks that strings decoded on demand through EXTSST match the eagerly decoded table.
+func TestLazySST(t *testing.T) {
+	t.Parallel()
+
+	for _, filePath := range []string{"testdata/bigtable.xls", "testdata/issue47.xls", "testdata/superstore.xls"} {
+		filePath := filePath
+
+		t.Run(filePath, func(t *testing.T) {
+			t.Parallel()
+
+			eager, err := Open(filePath)
+			if err != nil {
+				t.Fatalf("failed to open XLS file: %v", err)
+			}
+
+			for _, opts := range []*Options{{LazySST: true}, {LazySST: true, CacheSST: true}} {
+				lazy, err := OpenContext(context.Background(), filePath, opts)
+				if err != nil {
+					t.Fatalf("failed to open XLS file lazily: %v", err)
+				}
+
+				if !lazy.sstTable.lazy || lazy.sst != nil {
+					t.Fatalf("shared strings were decoded eagerly")
+				}
+
+				// Walk backwards so every lookup starts a fresh bucket scan
+				for i := len(eager.sst) - 1; i >= 0; i-- {
+					if got, want := lazy.sstString(uint32(i)), eager.sst[i]; got != want {
+						t.Fatalf("string %d: got %q, want %q", i, got, want)
+					}
+				}
+
+				if got := lazy.sstString(uint32(len(eager.sst))); got != "" {
+					t.Errorf("string past the end: got %q, want empty", got)
+				}
+			}
+		})
+	}
+}
+
+// TestMisalignedExtSST falls back to eager decoding when an EXTSST bucket does not point at
+// the first byte of its string.
+func TestMisalignedExtSST(t *testing.T) {
+	t.Parallel()
+
+	sst := []byte{2, 0, 0, 0, 2, 0, 0, 0}
+	sst = append(sst, 5, 0, 0)
+	sst = append(sst, "alpha"...)
+	sst = append(sst, 4, 0, 0)
+	sst = append(sst, "beta"...)
+
+	// the SST payload starts at offset 100 of the stream, so the strings at 108 and 116
+	extSST := func(offsets ...uint32) []byte {
+		data := []byte{1, 0}
+		for _, off := range offsets {
+			data = binary.LittleEndian.AppendUint32(data, off)
+			data = append(data, 0, 0, 0, 0)
+		}
+
+		return data
+	}
+
+	for _, tt := range []struct {
+		name    string
+		offsets []uint32
+		lazy    bool
+	}{
+		{"aligned", []uint32{108, 116}, true},
+		{"mid-string", []uint32{108, 117}, false},
+		{"stale", []uint32{100, 108}, false},
+	} {
+		wb := &WorkBook{rs: bytes.NewReader(append(make([]byte, 100), sst...)), opts: Options{LazySST: true}}
+		wb.addSST(sst, 100)
+		wb.addExtSST(extSST(tt.offsets...))
+		wb.finishSST()
+
+		if wb.sstTable.lazy != tt.lazy {
+			t.Errorf("%s: lazy = %v, want %v", tt.name, wb.sstTable.lazy, tt.lazy)
+		}
+
+		if got := []string{wb.sstString(0), wb.sstString(1)}; got[0] != "alpha" || got[1] != "beta" {
+			t.Errorf("%s: strings = %q, want alpha and beta", tt.name, got)
+		}
+	}
+}
+
+// TestRichTextRuns checks that the runs of a rich text string split it into segments
+// with their fonts, also when the runs continue in the next record.
+func TestRichTextRuns(t *testing.T) {
+	t.Parallel()
+
+	wb := &WorkBook{}
+	for _, name := range []string{"F0", "F1", "F2", "F3", "F5"} {
+		wb.Fonts = append(wb.Fonts, Font{Name: name})
+	}
+
+	sst := []byte{1, 0, 0, 0, 1, 0, 0, 0, 11, 0, 0x08, 2, 0}
+	sst = append(sst, "Hello world"...)
+	wb.addSST(sst, 0)
+	wb.addSSTContinue([]byte{6, 0, 5, 0, 9, 0, 1, 0}, int64(len(sst)+4))
+	wb.finishSST()
+
+	row := &Row{wb: wb, info: &rowInfo{}}
+	row.setCell(&LabelsstCol{Sst: 0})
+
+	if got := row.Col(0); got != "Hello world" {
+		t.Fatalf("Col(0) = %q, want %q", got, "Hello world")
+	}
+
+	want := []struct {
+		text string
+		font string
+	}{{"Hello ", ""}, {"wor", "F5"}, {"ld", "F1"}}
+
+	got := row.Segments(0)
+	if len(got) != len(want) {
+		t.Fatalf("got %d segments, want %d: %+v", len(got), len(want), got)
+	}
+
+	for i, seg := range got {
+		font := ""
+		if seg.Font != nil {
+			font = seg.Font.Name
+		}
+
+		if seg.Text != want[i].text || font != want[i].font {
+			t.Errorf("segment %d = %q in %q, want %q in %q", i, seg.Text, font, want[i].text, want[i].font)
+		}
+	}
+}
+
+// TestPhonetic checks that the phonetic reading of a shared string and its runs are decoded.
+func TestPhonetic(t *testing.T) {
+	t.Parallel()
+
+	le := func(values ...uint16) []byte {
+		res := make([]byte, 0, 2*len(values))
+		for _, v := range values {
+			res = append(res, byte(v), byte(v>>8))
+		}
+
+		return res
+	}
+
+	reading := utf16.Encode([]rune("トウキョウ"))
+
+	ext := le(0, 0x1, 2, uint16(len(reading)), uint16(len(reading)))
+	ext = append(ext, le(reading...)...)
+	ext = append(ext, le(0, 0, 1, 2, 1, 1)...)
+	ext = append(le(1, uint16(len(ext))), ext...)
+
+	sst := append(le(1, 0, 1, 0, 2), 0x05)
+	sst = append(sst, byte(len(ext)), 0, 0, 0)
+	sst = append(sst, le(utf16.Encode([]rune("東京"))...)...)
+	sst = append(sst, ext...)
+
+	wb := &WorkBook{}
+	wb.addSST(sst, 0)
+	wb.finishSST()
+
+	row := &Row{wb: wb, info: &rowInfo{}}
+	row.setCell(&LabelsstCol{Sst: 0})
+
+	if got := row.Col(0); got != "東京" {
+		t.Fatalf("Col(0) = %q, want %q", got, "東京")
+	}
+
+	phonetic := row.Phonetic(0)
+	if phonetic == nil {
+		t.Fatal("Phonetic(0) = nil")
+	}
+
+	if phonetic.Text != "トウキョウ" || phonetic.Type != 1 {
+		t.Errorf("Phonetic(0) = %q of type %d, want %q of type 1", phonetic.Text, phonetic.Type, "トウキョウ")
+	}
+
+	want := []PhoneticRun{{"トウ", "東", 0}, {"キョウ", "京", 1}}
+	if len(phonetic.Runs) != len(want) {
+		t.Fatalf("got %d runs, want %d: %+v", len(phonetic.Runs), len(want), phonetic.Runs)
+	}
+
+	for i, run := range phonetic.Runs {
+		if run != want[i] {
+			t.Errorf("run %d = %+v, want %+v", i, run, want[i])
+		}
+	}
+}
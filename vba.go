@@ -0,0 +1,339 @@
+//nolint:mnd
+package xls
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vstasn/ole2"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// vbaStorage is the storage of the VBA project of an Excel workbook.
+const vbaStorage = "_VBA_PROJECT_CUR"
+
+// errVBACompression reports compressed VBA data that does not follow MS-OVBA.
+var errVBACompression = errors.New("xls: invalid VBA compressed container")
+
+// VBAProject is the VBA project of a workbook with macros.
+type VBAProject struct {
+	Name string
+	// Codepage is the code page the module sources were stored in.
+	Codepage uint16
+	Modules  []VBAModule
+}
+
+// VBAModule is a module of a VBA project.
+type VBAModule struct {
+	Name string
+	Kind VBAModuleKind
+	// Source is the module's source code, including the Attribute lines the editor hides.
+	Source string
+}
+
+// VBAModuleKind tells standard modules from the class modules of sheets, user forms and classes.
+type VBAModuleKind int
+
+const (
+	VBAModuleStandard VBAModuleKind = iota + 1
+	VBAModuleClass
+	// VBAModuleDocument is the module of the workbook or of a sheet.
+	VBAModuleDocument
+	VBAModuleForm
+)
+
+func (k VBAModuleKind) String() string {
+	switch k {
+	case VBAModuleStandard:
+		return "standard"
+	case VBAModuleClass:
+		return "class"
+	case VBAModuleDocument:
+		return "document"
+	case VBAModuleForm:
+		return "form"
+	}
+
+	return "unknown"
+}
+
+// HasVBA tells whether the workbook has a VBA project storage, that is whether it carries macros.
+func (wb *WorkBook) HasVBA() bool {
+	return wb.vbaStorage() != nil
+}
+
+// vbaStorage returns the storage of the VBA project, or nil.
+func (wb *WorkBook) vbaStorage() *ole2.File {
+	for _, file := range wb.oleChildren(wb.oleRoot) {
+		if file.Type == ole2.USERSTORAGE && file.Name() == vbaStorage {
+			return file
+		}
+	}
+
+	return nil
+}
+
+// VBAProject returns the VBA project of the workbook with the source of its modules, or nil if
+// the workbook has none. It fails if the project's streams cannot be read or decompressed.
+func (wb *WorkBook) VBAProject() (*VBAProject, error) {
+	storage := wb.vbaStorage()
+	if storage == nil {
+		return nil, nil
+	}
+
+	streams := make(map[string][]byte)
+	if err := wb.readStorage(storage, "", streams, 0); err != nil {
+		return nil, fmt.Errorf("xls: VBA project: %w", err)
+	}
+
+	project, err := decodeVBAProject(streams)
+	if err != nil {
+		return nil, fmt.Errorf("xls: VBA project: %w", err)
+	}
+
+	return project, nil
+}
+
+// decodeVBAProject decodes a VBA project from the streams of its storage: the compressed dir
+// stream lists the modules, each module stream has the compressed source at the offset the
+// dir stream gives, and the PROJECT stream tells the kinds of modules apart.
+func decodeVBAProject(streams map[string][]byte) (*VBAProject, error) {
+	dir, err := decompressVBA(streams["VBA/dir"])
+	if err != nil {
+		return nil, fmt.Errorf("dir stream: %w", err)
+	}
+
+	type module struct {
+		VBAModule
+		stream string
+		offset uint32
+	}
+
+	var (
+		project = &VBAProject{Codepage: 1252}
+		modules []module
+		current *module
+	)
+
+	// records of an identifier and a size; the size of PROJECTVERSION leaves out its last two bytes
+	for off := 0; off+6 <= len(dir); {
+		id, size := binary.LittleEndian.Uint16(dir[off:]), int(binary.LittleEndian.Uint32(dir[off+2:]))
+		if id == 0x09 {
+			size = 6
+		}
+
+		if size > len(dir)-off-6 {
+			break
+		}
+
+		data := dir[off+6 : off+6+size]
+		off += 6 + size
+
+		switch id {
+		case 0x03: // PROJECTCODEPAGE
+			if len(data) >= 2 {
+				project.Codepage = binary.LittleEndian.Uint16(data)
+			}
+		case 0x04: // PROJECTNAME
+			project.Name = decodeCodepage(project.Codepage, data)
+		case 0x19: // MODULENAME
+			modules = append(modules, module{VBAModule: VBAModule{Name: decodeCodepage(project.Codepage, data)}})
+			current = &modules[len(modules)-1]
+		case 0x47: // MODULENAMEUNICODE
+			if current != nil {
+				current.Name = escherString(data)
+			}
+		case 0x1A: // MODULESTREAMNAME
+			if current != nil {
+				current.stream = decodeCodepage(project.Codepage, data)
+			}
+		case 0x31: // MODULEOFFSET
+			if current != nil && len(data) >= 4 {
+				current.offset = binary.LittleEndian.Uint32(data)
+			}
+		case 0x21: // MODULETYPE: procedural
+			if current != nil {
+				current.Kind = VBAModuleStandard
+			}
+		case 0x22: // MODULETYPE: document, class or designer
+			if current != nil {
+				current.Kind = VBAModuleClass
+			}
+		case 0x2B: // module terminator
+			current = nil
+		}
+	}
+
+	kinds := vbaModuleKinds(streams["PROJECT"])
+
+	for _, m := range modules {
+		if kind, ok := kinds[m.Name]; ok && m.Kind == VBAModuleClass {
+			m.Kind = kind
+		}
+
+		stream := streams["VBA/"+m.stream]
+		if int64(m.offset) > int64(len(stream)) {
+			return nil, fmt.Errorf("module %q: source offset %d past the end of the stream", m.Name, m.offset)
+		}
+
+		source, err := decompressVBA(stream[m.offset:])
+		if err != nil {
+			return nil, fmt.Errorf("module %q: %w", m.Name, err)
+		}
+
+		m.Source = decodeCodepage(project.Codepage, source)
+		project.Modules = append(project.Modules, m.VBAModule)
+	}
+
+	return project, nil
+}
+
+// vbaModuleKinds reads the kinds of the class modules from the PROJECT stream, which has
+// a line such as "Document=Sheet1/&H00000000", "Class=Invoice" or "BaseClass=UserForm1" for each.
+func vbaModuleKinds(project []byte) map[string]VBAModuleKind {
+	kinds := make(map[string]VBAModuleKind)
+
+	scanner := bufio.NewScanner(bytes.NewReader(project))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "Document":
+			name, _, _ := strings.Cut(value, "/")
+			kinds[name] = VBAModuleDocument
+		case "Class":
+			kinds[value] = VBAModuleClass
+		case "BaseClass":
+			kinds[value] = VBAModuleForm
+		}
+	}
+
+	return kinds
+}
+
+// decompressVBA decompresses an MS-OVBA compressed container: a signature byte, then chunks
+// of a 16-bit header and up to 4096 bytes of data. The header holds the chunk size less 3,
+// a signature of 0b011 and a flag for compressed chunks, which are sequences of a flag byte
+// and eight tokens: literal bytes, or copy tokens that repeat earlier data of the chunk.
+func decompressVBA(data []byte) ([]byte, error) {
+	if len(data) == 0 || data[0] != 0x01 {
+		return nil, errVBACompression
+	}
+
+	var out []byte
+
+	for pos := 1; pos+2 <= len(data); {
+		header := binary.LittleEndian.Uint16(data[pos:])
+		if header>>12&0x7 != 0x3 {
+			return nil, errVBACompression
+		}
+
+		end := min(pos+int(header&0x0FFF)+3, len(data))
+		chunk := data[pos+2 : end]
+		pos = end
+
+		if header&0x8000 == 0 {
+			out = append(out, chunk...)
+
+			continue
+		}
+
+		start := len(out)
+
+		for i := 0; i < len(chunk); {
+			flags := chunk[i]
+			i++
+
+			for bit := 0; bit < 8 && i < len(chunk); bit++ {
+				if flags&(1<<bit) == 0 {
+					out = append(out, chunk[i])
+					i++
+
+					continue
+				}
+
+				if i+2 > len(chunk) {
+					return nil, errVBACompression
+				}
+
+				token := binary.LittleEndian.Uint16(chunk[i:])
+				i += 2
+
+				// the more of the chunk is decompressed, the more bits the offset takes
+				bitCount := 4
+				for 1<<bitCount < len(out)-start {
+					bitCount++
+				}
+
+				length := int(token&(0xFFFF>>bitCount)) + 3
+				offset := int(token>>(16-bitCount)) + 1
+
+				if offset > len(out)-start {
+					return nil, errVBACompression
+				}
+
+				for i := 0; i < length; i++ {
+					out = append(out, out[len(out)-offset])
+				}
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// decodeCodepage decodes text stored in a Windows code page.
+func decodeCodepage(codepage uint16, data []byte) string {
+	var enc encoding.Encoding
+
+	switch codepage {
+	case 65001:
+		return string(data)
+	case 874:
+		enc = charmap.Windows874
+	case 932:
+		enc = japanese.ShiftJIS
+	case 936:
+		enc = simplifiedchinese.GBK
+	case 949:
+		enc = korean.EUCKR
+	case 950:
+		enc = traditionalchinese.Big5
+	case 1250:
+		enc = charmap.Windows1250
+	case 1251:
+		enc = charmap.Windows1251
+	case 1253:
+		enc = charmap.Windows1253
+	case 1254:
+		enc = charmap.Windows1254
+	case 1255:
+		enc = charmap.Windows1255
+	case 1256:
+		enc = charmap.Windows1256
+	case 1257:
+		enc = charmap.Windows1257
+	case 1258:
+		enc = charmap.Windows1258
+	case 10000:
+		enc = charmap.Macintosh
+	default:
+		enc = charmap.Windows1252
+	}
+
+	out, _ := enc.NewDecoder().Bytes(data)
+
+	return string(out)
+}
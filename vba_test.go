@@ -0,0 +1,116 @@
+package xls
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDecompressVBA decompresses the example of MS-OVBA, which has copy tokens of several lengths.
+func TestDecompressVBA(t *testing.T) {
+	t.Parallel()
+
+	compressed := []byte{
+		0x01, 0x2F, 0xB0, 0x00, 0x23, 0x61, 0x61, 0x61, 0x62, 0x63, 0x64, 0x65, 0x82, 0x66, 0x00, 0x70,
+		0x61, 0x67, 0x68, 0x69, 0x6A, 0x01, 0x38, 0x08, 0x61, 0x6B, 0x6C, 0x00, 0x30, 0x6D, 0x6E, 0x6F,
+		0x70, 0x06, 0x71, 0x02, 0x70, 0x04, 0x10, 0x72, 0x73, 0x74, 0x75, 0x76, 0x10, 0x77, 0x78, 0x79,
+		0x7A, 0x00, 0x3C,
+	}
+
+	got, err := decompressVBA(compressed)
+	if err != nil {
+		t.Fatalf("decompressVBA failed: %v", err)
+	}
+
+	if want := "#aaabcdefaaaaghijaaaaaklaaamnopqaaaaaaaaaaaarstuvwxyzaaa"; string(got) != want {
+		t.Errorf("decompressVBA() = %q, want %q", got, want)
+	}
+
+	if _, err := decompressVBA([]byte{0x01, 0x00, 0x00}); err == nil {
+		t.Errorf("a chunk without its signature was accepted")
+	}
+}
+
+func TestVBAProject(t *testing.T) {
+	t.Parallel()
+
+	wb, err := Open("testdata/superstore.xls")
+	if err != nil {
+		t.Fatalf("failed to open XLS file: %v", err)
+	}
+
+	if !wb.HasVBA() {
+		t.Fatalf("HasVBA() = false")
+	}
+
+	project, err := wb.VBAProject()
+	if err != nil {
+		t.Fatalf("VBAProject() failed: %v", err)
+	}
+
+	if project.Name != "VBAProject" || len(project.Modules) != 1 {
+		t.Fatalf("VBAProject() = %q with %d modules", project.Name, len(project.Modules))
+	}
+
+	module := project.Modules[0]
+	if module.Name != "Module1" || module.Kind != VBAModuleStandard ||
+		!strings.HasPrefix(module.Source, `Attribute VB_Name = "Module1"`) || !strings.Contains(module.Source, "Sub Macro3()") {
+		t.Errorf("unexpected module %s (%s):\n%s", module.Name, module.Kind, module.Source)
+	}
+}
+
+// TestVBAStorage reads the streams of the VBA project storage, with those of its VBA storage.
+func TestVBAStorage(t *testing.T) {
+	t.Parallel()
+
+	wb, err := Open("testdata/superstore.xls")
+	if err != nil {
+		t.Fatalf("failed to open XLS file: %v", err)
+	}
+
+	storage := wb.vbaStorage()
+	if storage == nil {
+		t.Fatalf("%s storage not found", vbaStorage)
+	}
+
+	streams := make(map[string][]byte)
+	if err := wb.readStorage(storage, "", streams, 0); err != nil {
+		t.Fatalf("readStorage failed: %v", err)
+	}
+
+	for _, name := range []string{"PROJECT", "VBA/dir", "VBA/Module1"} {
+		if len(streams[name]) == 0 {
+			t.Errorf("stream %q is missing", name)
+		}
+	}
+}
+
+func TestNoVBA(t *testing.T) {
+	t.Parallel()
+
+	wb, err := Open("testdata/times.xls")
+	if err != nil {
+		t.Fatalf("failed to open XLS file: %v", err)
+	}
+
+	if project, err := wb.VBAProject(); wb.HasVBA() || project != nil || err != nil {
+		t.Errorf("VBAProject() = %v, %v, want none", project, err)
+	}
+}
+
+func TestVBAModuleKinds(t *testing.T) {
+	t.Parallel()
+
+	project := "ID=\"{00000000-0000-0000-0000-000000000000}\"\r\nDocument=ThisWorkbook/&H00000000\r\n" +
+		"Module=Module1\r\nClass=Invoice\r\nBaseClass=UserForm1\r\nName=\"VBAProject\"\r\n"
+
+	kinds := vbaModuleKinds([]byte(project))
+	for name, want := range map[string]VBAModuleKind{
+		"ThisWorkbook": VBAModuleDocument,
+		"Invoice":      VBAModuleClass,
+		"UserForm1":    VBAModuleForm,
+	} {
+		if kinds[name] != want {
+			t.Errorf("kind of %s = %s, want %s", name, kinds[name], want)
+		}
+	}
+}
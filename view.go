@@ -0,0 +1,137 @@
+//nolint:mnd
+package xls
+
+import "encoding/binary"
+
+// SheetView holds how a sheet is displayed: what is shown, where it is scrolled to,
+// frozen panes or splits, zoom and the selection. It comes from the WINDOW2, PANE, SCL
+// and SELECTION records.
+type SheetView struct {
+	ShowFormulas  bool
+	ShowGridlines bool
+	ShowHeaders   bool
+	ShowZeros     bool
+	ShowOutline   bool
+	RightToLeft   bool
+	// TabSelected is set for every sheet whose tab is selected, not only the active one.
+	TabSelected      bool
+	PageBreakPreview bool
+
+	// TopRow and LeftCol are the first visible row and column of the top-left pane.
+	TopRow, LeftCol int
+
+	// Frozen is set when the panes are frozen; FrozenRows and FrozenCols then tell
+	// how many rows and columns stay in place while scrolling.
+	Frozen     bool
+	FrozenRows int
+	FrozenCols int
+	// SplitX and SplitY are the positions of unfrozen splits from the top-left corner, in points.
+	SplitX, SplitY float64
+	// PaneTopRow and PaneLeftCol are the first visible row of the bottom panes and
+	// column of the right panes.
+	PaneTopRow, PaneLeftCol int
+	// ActivePane is the pane with the cursor: 0 bottom-right, 1 top-right, 2 bottom-left, 3 top-left.
+	ActivePane int
+
+	// Zoom is the magnification in percent.
+	Zoom int
+
+	// ActiveRow and ActiveCol locate the cell with the cursor.
+	ActiveRow, ActiveCol int
+	// Selection lists the selected ranges of the active pane.
+	Selection []CellRange
+}
+
+// View returns the display settings of the sheet.
+func (w *WorkSheet) View() SheetView {
+	return w.view
+}
+
+// setWindow2 decodes a WINDOW2 record.
+func (w *WorkSheet) setWindow2(data []byte) {
+	if len(data) < 6 {
+		return
+	}
+
+	options := binary.LittleEndian.Uint16(data)
+	v := &w.view
+
+	v.ShowFormulas = options&0x1 != 0
+	v.ShowGridlines = options&0x2 != 0
+	v.ShowHeaders = options&0x4 != 0
+	v.Frozen = options&0x8 != 0
+	v.ShowZeros = options&0x10 != 0
+	v.RightToLeft = options&0x40 != 0
+	v.ShowOutline = options&0x80 != 0
+	v.TabSelected = options&0x200 != 0
+	v.PageBreakPreview = options&0x800 != 0
+	v.TopRow = int(binary.LittleEndian.Uint16(data[2:]))
+	v.LeftCol = int(binary.LittleEndian.Uint16(data[4:]))
+	v.ActivePane = 3
+
+	// The normal zoom, unless an SCL record follows
+	if v.Zoom = 100; len(data) >= 14 {
+		if zoom := binary.LittleEndian.Uint16(data[12:]); zoom != 0 {
+			v.Zoom = int(zoom)
+		}
+	}
+
+	w.Selected = options&0x400 != 0
+}
+
+// setPane decodes a PANE record. Frozen panes count rows and columns, splits are in twips.
+func (w *WorkSheet) setPane(data []byte) {
+	if len(data) < 9 {
+		return
+	}
+
+	v := &w.view
+	x, y := binary.LittleEndian.Uint16(data), binary.LittleEndian.Uint16(data[2:])
+
+	if v.Frozen {
+		v.FrozenCols, v.FrozenRows = int(x), int(y)
+	} else {
+		v.SplitX, v.SplitY = float64(x)/twipsPerPoint, float64(y)/twipsPerPoint
+	}
+
+	v.PaneTopRow = int(binary.LittleEndian.Uint16(data[4:]))
+	v.PaneLeftCol = int(binary.LittleEndian.Uint16(data[6:]))
+	v.ActivePane = int(data[8] & 0x3)
+}
+
+// setScl decodes an SCL record, the zoom as a fraction.
+func (w *WorkSheet) setScl(data []byte) {
+	if len(data) < 4 {
+		return
+	}
+
+	num, denom := int16(binary.LittleEndian.Uint16(data)), int16(binary.LittleEndian.Uint16(data[2:]))
+	if num > 0 && denom > 0 {
+		w.view.Zoom = int(num) * 100 / int(denom)
+	}
+}
+
+// addSelection decodes a SELECTION record, keeping the one of the active pane.
+// The PANE record precedes the SELECTION records, so the active pane is known.
+func (w *WorkSheet) addSelection(data []byte) {
+	if len(data) < 9 || int(data[0]) != w.view.ActivePane {
+		return
+	}
+
+	v := &w.view
+	v.ActiveRow = int(binary.LittleEndian.Uint16(data[1:]))
+	v.ActiveCol = int(binary.LittleEndian.Uint16(data[3:]))
+
+	count := min(int(binary.LittleEndian.Uint16(data[7:])), (len(data)-9)/6)
+	v.Selection = make([]CellRange, count)
+
+	for i := range v.Selection {
+		ref := data[9+6*i:]
+		v.Selection[i] = CellRange{
+			FirstRowB: binary.LittleEndian.Uint16(ref),
+			LastRowB:  binary.LittleEndian.Uint16(ref[2:]),
+			FristColB: uint16(ref[4]),
+			LastColB:  uint16(ref[5]),
+		}
+	}
+}
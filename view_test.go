@@ -0,0 +1,48 @@
+package xls
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestView(t *testing.T) {
+	t.Parallel()
+
+	wb, err := Open("testdata/superstore.xls")
+	if err != nil {
+		t.Fatalf("failed to open XLS file: %v", err)
+	}
+
+	view := wb.GetSheet(0).View()
+	if !view.ShowGridlines || view.Frozen || view.TopRow != 8643 || view.Zoom != 100 || view.ActiveRow != 8659 || view.ActiveCol != 6 {
+		t.Errorf("unexpected view %+v", view)
+	}
+}
+
+// TestFrozenPanes feeds the records Excel writes for a sheet with one frozen row and two frozen columns.
+func TestFrozenPanes(t *testing.T) {
+	t.Parallel()
+
+	sheet := &WorkSheet{wb: new(WorkBook)}
+	sheet.parseBof(bof{ID: 0x23E}, []byte{0xBE, 0x07, 0, 0, 0, 0, 0x40, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, nil)
+	sheet.parseBof(bof{ID: 0xA0}, []byte{3, 0, 2, 0}, nil)
+	sheet.parseBof(bof{ID: 0x41}, []byte{2, 0, 1, 0, 1, 0, 2, 0, 0, 0}, nil)
+	sheet.parseBof(bof{ID: 0x1D}, []byte{3, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0}, nil)
+	sheet.parseBof(bof{ID: 0x1D}, []byte{0, 5, 0, 3, 0, 0, 0, 1, 0, 4, 0, 6, 0, 3, 4}, nil)
+
+	view := sheet.View()
+	want := SheetView{
+		ShowGridlines: true, ShowHeaders: true, ShowZeros: true, ShowOutline: true,
+		TabSelected: true, Frozen: true, FrozenRows: 1, FrozenCols: 2,
+		PaneTopRow: 1, PaneLeftCol: 2, ActivePane: 0, Zoom: 150, ActiveRow: 5, ActiveCol: 3,
+		Selection: []CellRange{{FirstRowB: 4, LastRowB: 6, FristColB: 3, LastColB: 4}},
+	}
+
+	if !reflect.DeepEqual(view, want) {
+		t.Errorf("got  %+v\nwant %+v", view, want)
+	}
+
+	if !sheet.Selected {
+		t.Error("Selected = false, want true")
+	}
+}
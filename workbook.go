@@ -2,11 +2,14 @@
 package xls
 
 import (
+	"bytes"
+	"context"
 	"encoding/binary"
 	"io"
 	"os"
 	"unicode/utf16"
 
+	"github.com/vstasn/ole2"
 	"golang.org/x/text/encoding/charmap"
 )
 
@@ -19,41 +22,107 @@ type WorkBook struct {
 	Fonts    []Font
 	Formats  map[uint16]*Format
 	// All the sheets from the workbook
-	sheets        []*WorkSheet
-	Author        string
-	rs            io.ReadSeeker
-	sstParser     *sstParser
-	sst           []string
-	continueUtf16 uint16
-	continueRich  uint16
-	continueApsb  uint32
-	dateMode      uint16
+	sheets       []*WorkSheet
+	Author       string
+	rs           io.ReadSeeker
+	sstTable     *sstTable
+	sst          []string
+	dateMode     uint16
+	opts         Options
+	locale       *locale
+	decodedBytes int64
+	limitErr     error
+	strReader    bytes.Reader // reused to decode strings inside global records
+	names        []definedName
+	palette      []uint32 // PALETTE colors from index 8 on, as 0xRRGGBB
+	protection   WorkbookProtection
+	encryption   string   // FILEPASS: "xor", "rc4" or "cryptoapi", empty if not encrypted
+	drawingGroup []byte   // MSODRAWINGGROUP and its CONTINUE records
+	drawingOpen  bool     // the last record was part of the drawing group
+	blips        [][]byte // BSE records of the drawing group, decoded on demand
+	supBooks     []supBook
+	externSheets []externSheet
+	ole          *ole2.Ole    // the OLE2 container, which embedded objects are read from
+	oleDir       []*ole2.File // its directory entries
+	oleRoot      *ole2.File
 }
 
 // read workbook from ole2 file
-func newWorkBookFromOle2(readSeeker io.ReadSeeker) *WorkBook {
+func newWorkBookFromOle2(ctx context.Context, readSeeker io.ReadSeeker, opts *Options) (*WorkBook, error) {
 	workBook := new(WorkBook)
 	workBook.Formats = make(map[uint16]*Format)
 	// wb.bts = bts
 	workBook.rs = readSeeker
 	workBook.sheets = make([]*WorkSheet, 0)
-	workBook.Parse(readSeeker)
 
-	return workBook
+	if opts != nil {
+		workBook.opts = *opts
+	}
+
+	var err error
+	if workBook.locale, err = lookupLocale(workBook.opts.Locale); err != nil {
+		return nil, err
+	}
+
+	if err = workBook.parse(ctx, readSeeker, false); err != nil {
+		return nil, err
+	}
+
+	return workBook, nil
 }
 
+// Parse reads the workbook records from buf, up to the end of the stream.
 func (wb *WorkBook) Parse(buf io.ReadSeeker) {
-	currentBOF := new(bof)
-	previousBOF := new(bof)
-	sstOffset := 0
+	_ = wb.ParseContext(context.Background(), buf)
+}
+
+// ParseContext is like Parse, but parsing stops with ctx.Err() as soon as ctx is done.
+// It returns the error that stopped it, such as the *LimitError of an exceeded limit.
+func (wb *WorkBook) ParseContext(ctx context.Context, buf io.ReadSeeker) error {
+	return wb.parse(ctx, buf, true)
+}
+
+// parse reads the workbook globals, from the first BOF up to the matching EOF record.
+// With toEnd, the records of the sheet substreams after it are read to the end of the stream too;
+// they are only parsed when a sheet is requested.
+func (wb *WorkBook) parse(ctx context.Context, buf io.ReadSeeker, toEnd bool) error {
+	records, err := acquireRecordReader(buf, 0)
+	if err != nil {
+		return err
+	}
+	defer records.release()
+
+	tracker := newRecordTracker(ctx, wb, "")
+	globals := true
 
 	for {
-		if err := binary.Read(buf, binary.LittleEndian, currentBOF); err == nil {
-			sstOffset, previousBOF, currentBOF = wb.parseBof(buf, currentBOF, previousBOF, sstOffset)
-		} else {
+		header, data, pos, err := records.next()
+		if err != nil {
 			break
 		}
+
+		if err := tracker.next(header.Size); err != nil {
+			return err
+		}
+
+		if !globals {
+			continue
+		}
+
+		wb.parseBof(header, data, pos)
+
+		if header.ID == 0xa { // EOF
+			if !toEnd {
+				break
+			}
+
+			globals = false
+		}
 	}
+
+	wb.finishSST()
+
+	return tracker.finish()
 }
 
 func (wb *WorkBook) addXf(xf st_xf_data) {
@@ -81,6 +150,10 @@ func decodeWindows1251(enc []byte) string {
 }
 
 func (wb *WorkBook) getString(buf io.ReadSeeker, size uint16) (res string, err error) {
+	if err = wb.checkLimit("MaxStringLength", int64(size), int64(wb.opts.Limits.MaxStringLength)); err != nil {
+		return "", err
+	}
+
 	if wb.Is5ver {
 		bts := make([]byte, size)
 		_, err = buf.Read(bts)
@@ -94,16 +167,10 @@ func (wb *WorkBook) getString(buf io.ReadSeeker, size uint16) (res string, err e
 
 		if flag&0x8 != 0 {
 			err = binary.Read(buf, binary.LittleEndian, &richtextNum)
-		} else if wb.continueRich > 0 {
-			richtextNum = wb.continueRich
-			wb.continueRich = 0
 		}
 
 		if flag&0x4 != 0 {
 			err = binary.Read(buf, binary.LittleEndian, &phoneticSize)
-		} else if wb.continueApsb > 0 {
-			phoneticSize = wb.continueApsb
-			wb.continueApsb = 0
 		}
 
 		if flag&0x1 != 0 {
@@ -122,16 +189,11 @@ func (wb *WorkBook) getString(buf io.ReadSeeker, size uint16) (res string, err e
 
 			runes = utf16.Decode(bts[:i])
 			res = string(runes)
-
-			if i < size {
-				wb.continueUtf16 = size - i
-			}
 		} else {
 			bts := make([]byte, size)
 			var n int
 			n, err = buf.Read(bts)
 			if uint16(n) < size {
-				wb.continueUtf16 = size - uint16(n)
 				err = io.EOF
 			}
 
@@ -144,31 +206,9 @@ func (wb *WorkBook) getString(buf io.ReadSeeker, size uint16) (res string, err e
 			res = string(runes)
 		}
 
-		if richtextNum > 0 {
-			var bts []byte
-			var seekSize int64
-
-			if wb.Is5ver {
-				seekSize = int64(2 * richtextNum)
-			} else {
-				seekSize = int64(4 * richtextNum)
-			}
-
-			bts = make([]byte, seekSize)
-			err = binary.Read(buf, binary.LittleEndian, bts)
-
-			if err == io.EOF {
-				wb.continueRich = richtextNum
-			}
-		}
-
-		if phoneticSize > 0 {
-			bts := make([]byte, phoneticSize)
-			err = binary.Read(buf, binary.LittleEndian, bts)
-
-			if err == io.EOF {
-				wb.continueApsb = phoneticSize
-			}
+		// Rich text runs and phonetic data only occur in the SST, which is decoded separately.
+		if richtextNum > 0 || phoneticSize > 0 {
+			_, err = buf.Seek(4*int64(richtextNum)+int64(phoneticSize), io.SeekCurrent)
 		}
 	}
 	return
@@ -176,31 +216,58 @@ func (wb *WorkBook) getString(buf io.ReadSeeker, size uint16) (res string, err e
 
 func (wb *WorkBook) addSheet(sheet *boundsheet, buf io.ReadSeeker) {
 	name, _ := wb.getString(buf, uint16(sheet.Name))
-	wb.sheets = append(wb.sheets, &WorkSheet{bs: sheet, Name: name, wb: wb, Visibility: TWorkSheetVisibility(sheet.Visible)})
+	wb.sheets = append(wb.sheets, &WorkSheet{
+		bs:         sheet,
+		Name:       name,
+		wb:         wb,
+		Visibility: TWorkSheetVisibility(sheet.Visible),
+		Kind:       SheetKind(sheet.Type),
+	})
 }
 
 // reading a sheet from the compress file to memory, you should call this before you try to get anything from sheet
-func (wb *WorkBook) prepareSheet(sheet *WorkSheet) {
-	_, err := wb.rs.Seek(int64(sheet.bs.Filepos), 0)
-	if err != nil {
-		panic("xls: prepareSheet: " + err.Error())
+func (wb *WorkBook) prepareSheet(ctx context.Context, sheet *WorkSheet) error {
+	return sheet.parse(ctx)
+}
+
+// Sheet returns the sheet with the given number without parsing its cells, or nil if there is none.
+// Use WorkSheet.ReadRows on it to decode a range of rows, or GetSheet to load the whole sheet.
+func (wb *WorkBook) Sheet(num int) *WorkSheet {
+	if num < 0 || num >= len(wb.sheets) {
+		return nil
 	}
 
-	sheet.parse(wb.rs)
+	return wb.sheets[num]
 }
 
-// Get one sheet by its number
+// Get one sheet by its number. It returns nil if there is no such sheet
+// or the sheet could not be parsed; use GetSheetContext to see the error.
+// Chart and module sheets are returned without cells; see WorkSheet.Kind.
 func (wb *WorkBook) GetSheet(num int) *WorkSheet {
-	if num < len(wb.sheets) {
-		s := wb.sheets[num]
-		if !s.parsed {
-			wb.prepareSheet(s)
-		}
+	sheet, err := wb.GetSheetContext(context.Background(), num)
+	if err != nil {
+		return nil
+	}
 
-		return s
+	return sheet
+}
+
+// GetSheetContext parses the sheet with the given number, stopping with ctx.Err()
+// as soon as ctx is done. Progress is reported through the Options the workbook was opened with.
+// It returns nil and no error if there is no such sheet.
+func (wb *WorkBook) GetSheetContext(ctx context.Context, num int) (*WorkSheet, error) {
+	if num < 0 || num >= len(wb.sheets) {
+		return nil, nil
 	}
 
-	return nil
+	s := wb.sheets[num]
+	if !s.parsed {
+		if err := wb.prepareSheet(ctx, s); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
 }
 
 // Get the number of all sheets, look into example
@@ -209,13 +276,9 @@ func (wb *WorkBook) NumSheets() int {
 }
 
 func (wb *WorkBook) GetSheetByName(sheetName string) *WorkSheet {
-	for _, sheet := range wb.sheets {
+	for i, sheet := range wb.sheets {
 		if sheet.Name == sheetName {
-			if !sheet.parsed {
-				wb.prepareSheet(sheet)
-			}
-
-			return sheet
+			return wb.GetSheet(i)
 		}
 	}
 
@@ -223,13 +286,7 @@ func (wb *WorkBook) GetSheetByName(sheetName string) *WorkSheet {
 }
 
 func (wb *WorkBook) GetFirstSheet() *WorkSheet {
-	sheet := wb.sheets[0]
-
-	if !sheet.parsed {
-		wb.prepareSheet(sheet)
-	}
-
-	return sheet
+	return wb.GetSheet(0)
 }
 
 // ReadAllCells reads all cell data from the workbook up to a maximum number of rows.
@@ -242,47 +299,32 @@ func (wb *WorkBook) ReadAllCells(maxRowsTotal int) [][]string {
 			break
 		}
 
-		wb.prepareSheet(sheet)
-
-		if sheet.MaxRow == 0 {
+		if err := wb.prepareSheet(context.Background(), sheet); err != nil {
 			continue
 		}
 
-		remaining := maxRowsTotal - len(res)
-		rowCount := sheet.MaxRow + 1
-
-		if remaining < int(rowCount) {
-			rowCount = uint16(remaining)
+		if sheet.MaxRow == 0 {
+			continue
 		}
 
+		rowCount := min(maxRowsTotal-len(res), int(sheet.MaxRow)+1)
 		temp := make([][]string, rowCount)
 
 		for rowIndex, row := range sheet.rows {
-			if rowIndex >= rowCount {
-				break
+			if int(rowIndex) >= rowCount {
+				continue
 			}
 
-			if len(row.cols) == 0 {
+			if len(row.cells) == 0 {
 				continue
 			}
 
-			// Pre-allocate a reasonably sized slice
-			data := make([]string, 0, 10)
-
-			for _, col := range row.cols {
-				str := col.String(wb)
-				last := col.LastCol()
-				first := col.FirstCol()
-
-				// Extend slice if needed
-				if len(data) <= int(last) {
-					newData := make([]string, int(last)+1)
-					copy(newData, data)
-					data = newData
-				}
+			data := make([]string, int(row.first)+len(row.cells))
 
-				for j, s := range str {
-					data[int(first)+j] = s
+			for i, ch := range row.cells {
+				if ch != nil {
+					col := int(row.first) + i
+					data[col] = ch.cellString(wb, col-int(ch.FirstCol()))
 				}
 			}
 
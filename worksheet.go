@@ -3,10 +3,11 @@ package xls
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"fmt"
-	"io"
-	"unicode/utf16"
+	"image/color"
+	"math"
 )
 
 type TWorkSheetVisibility byte
@@ -31,11 +32,47 @@ type WorkSheet struct {
 	Name       string
 	Selected   bool
 	Visibility TWorkSheetVisibility
-	rows       map[uint16]*Row
+	// Kind tells worksheets from chart, macro and module sheets. Only worksheets and macro sheets have cells.
+	Kind SheetKind
+	rows map[uint16]*Row
 	// NOTICE: this is the max row number of the sheet, so it should be count -1
-	MaxRow      uint16
-	parsed      bool
-	rightToLeft bool
+	MaxRow    uint16
+	parsed    bool
+	cells     int
+	strReader bytes.Reader // reused to decode strings inside cell records
+
+	view       SheetView
+	pageSetup  PageSetup
+	protection SheetProtection
+	autoFilter *AutoFilter
+	filterMode bool // FILTERMODE: the autofilter hides rows
+	sort       *SortState
+	drawing    []byte // MSODRAWING records, which together form the drawing container
+	charts     []Chart
+	objects    []oleObject // OBJ records of embedded OLE objects
+	objCount   int         // OBJ records so far
+	tabColor   int         // SHEETEXT palette index
+	tabRGB     *color.RGBA // SHEETEXT RGB color, if given
+	dims       *Dimensions // DIMENSIONS, if the sheet has the record
+
+	columns       []ColumnInfo
+	defColWidth   uint16 // DEFCOLWIDTH, in characters without padding
+	standardWidth uint16 // STANDARDWIDTH, in 1/256 of a character
+
+	defaultRowHeight uint16 // DEFAULTROWHEIGHT, in twips
+	defaultRowHidden bool
+}
+
+// index returns the position of the sheet in the workbook, or -1. Sheets decoded into a
+// scratch sheet are found through their BOUNDSHEET record.
+func (w *WorkSheet) index() int {
+	for i, sheet := range w.wb.sheets {
+		if sheet == w || (w.bs != nil && sheet.bs == w.bs) {
+			return i
+		}
+	}
+
+	return -1
 }
 
 func (w *WorkSheet) Row(i int) *Row {
@@ -47,86 +84,192 @@ func (w *WorkSheet) Row(i int) *Row {
 	return row
 }
 
-func (w *WorkSheet) parse(buf io.ReadSeeker) {
+func (w *WorkSheet) parse(ctx context.Context) error {
 	w.rows = make(map[uint16]*Row)
+	w.MaxRow = 0
+	w.cells = 0
+	w.columns = nil
+	w.defColWidth, w.standardWidth = 0, 0
+	w.defaultRowHeight, w.defaultRowHidden = 0, false
+	w.view = SheetView{}
+	w.pageSetup = defaultPageSetup
+	w.protection = SheetProtection{Allowed: defaultSheetActions}
+	w.tabColor, w.tabRGB = 0, nil
+	w.dims = nil
+	w.autoFilter, w.filterMode, w.sort = nil, false, nil
+	w.drawing, w.charts = nil, nil
+	w.objects, w.objCount = nil, 0
+
+	records, err := acquireRecordReader(w.wb.rs, int64(w.bs.Filepos))
+	if err != nil {
+		return fmt.Errorf("xls: prepareSheet: %w", err)
+	}
+	defer records.release()
 
-	b := new(bof)
-	var bofPre *bof
 	var colPre interface{}
+	tracker := newRecordTracker(ctx, w.wb, w.Name)
+
+	// Embedded charts are substreams of their own, from a BOF to the matching EOF.
+	// A chart sheet is a chart substream too, which also holds the sheet's own records.
+	var chart *chartParser
+
+	depth := 0
 
 	for {
-		if err := binary.Read(buf, binary.LittleEndian, b); err == nil {
-			bofPre, colPre = w.parseBof(buf, b, bofPre, colPre)
+		b, data, _, err := records.next()
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+
+		if err := tracker.next(b.Size); err != nil {
+			return err
+		}
+
+		if b.ID == 0x809 { // BOF
+			depth++
+			if depth > 1 || w.Kind == SheetKindChart {
+				chart = &chartParser{wb: w.wb}
+			}
+		}
+
+		if chart != nil {
+			chart.parse(b.ID, data)
+		}
 
-			if b.ID == 0xa {
+		if depth <= 1 {
+			colPre = w.parseBof(b, data, colPre)
+		}
+
+		if b.ID == 0xa { // EOF
+			if chart != nil && (depth > 1 || w.Kind == SheetKindChart) {
+				w.charts = append(w.charts, chart.chart)
+				chart = nil
+			}
+
+			if depth--; depth <= 0 {
 				break
 			}
-		} else {
-			fmt.Println(err)
-			break
 		}
 	}
 
+	if err := tracker.finish(); err != nil {
+		return err
+	}
+
+	if w.wb.opts.SkipFilteredRows {
+		w.dropFilteredRows()
+	}
+
 	w.parsed = true
+
+	return nil
 }
 
-func (w *WorkSheet) parseBof(buf io.ReadSeeker, b *bof, pre *bof, colPre interface{}) (*bof, interface{}) {
+// parseBof decodes one record of the sheet substream. data is only valid during the call.
+// It returns the cell the record produced, if any, for records that refer to the previous cell.
+func (w *WorkSheet) parseBof(b bof, data []byte, colPre interface{}) interface{} {
 	var col interface{}
-	bts := make([]byte, b.Size)
-	binary.Read(buf, binary.LittleEndian, bts)
-	buf = bytes.NewReader(bts)
+
+	if w.parsePageSetup(b.ID, data) || w.parseProtection(b.ID, data) || w.parseAutoFilter(b.ID, data) ||
+		(!w.Kind.hasCells() && isCellRecord(b.ID)) {
+		return nil
+	}
 
 	switch b.ID {
 	// case 0x0E5: //MERGEDCELLS
 	// ws.mergedCells(buf)
 	case 0x23E: // WINDOW2
-		var sheetOptions, firstVisibleRow, firstVisibleColumn uint16
-		binary.Read(buf, binary.LittleEndian, &sheetOptions)
-		binary.Read(buf, binary.LittleEndian, &firstVisibleRow)    // not valuable
-		binary.Read(buf, binary.LittleEndian, &firstVisibleColumn) // not valuable
-		// buf.Seek(int64(b.Size)-2*3, 1)
-		w.rightToLeft = (sheetOptions & 0x40) != 0
-		w.Selected = (sheetOptions & 0x400) != 0
+		w.setWindow2(data)
+	case 0x200: // DIMENSIONS
+		w.setDimensions(data)
+	case 0xEC: // MSODRAWING
+		w.drawing = append(w.drawing, data...)
+	case 0x5D: // OBJ
+		w.addObject(data)
+	case 0x862: // SHEETEXT
+		w.setSheetExt(data)
+	case 0x41: // PANE
+		w.setPane(data)
+	case 0xA0: // SCL
+		w.setScl(data)
+	case 0x1D: // SELECTION
+		w.addSelection(data)
+	case 0x7D: // COLINFO
+		w.addColInfo(data)
+	case 0x55: // DEFCOLWIDTH
+		if len(data) >= 2 {
+			w.defColWidth = binary.LittleEndian.Uint16(data)
+		}
+	case 0x99: // STANDARDWIDTH
+		if len(data) >= 2 {
+			w.standardWidth = binary.LittleEndian.Uint16(data)
+		}
+	case 0x225: // DEFAULTROWHEIGHT
+		w.setDefaultRowHeight(data)
 	case 0x208: // ROW
-		r := new(rowInfo)
-		binary.Read(buf, binary.LittleEndian, r)
-		w.addRow(r)
+		if len(data) < 16 {
+			break
+		}
+
+		w.addRow(decodeRowInfo(data))
 	case 0x0BD: // MULRK
-		mulRkCol := new(MulrkCol)
-		size := (b.Size - 6) / 6
-		binary.Read(buf, binary.LittleEndian, &mulRkCol.Col)
-		mulRkCol.Xfrks = make([]XfRk, size)
-		for i := uint16(0); i < size; i++ {
-			binary.Read(buf, binary.LittleEndian, &mulRkCol.Xfrks[i])
+		if len(data) < 12 {
+			break
+		}
+
+		size := (len(data) - 6) / 6
+		mulRkCol := &MulrkCol{Col: decodeCol(data), Xfrks: make([]XfRk, size)}
+
+		for i := range mulRkCol.Xfrks {
+			mulRkCol.Xfrks[i] = decodeXfRk(data[4+6*i:])
 		}
-		binary.Read(buf, binary.LittleEndian, &mulRkCol.LastColB)
+
+		// Trust the entries actually present, not the claimed last column.
+		mulRkCol.LastColB = mulRkCol.FirstColB + uint16(size) - 1
 		col = mulRkCol
 	case 0x0BE: // MULBLANK
-		mulBlankCol := new(MulBlankCol)
-		size := (b.Size - 6) / 2
-		binary.Read(buf, binary.LittleEndian, &mulBlankCol.Col)
-		mulBlankCol.Xfs = make([]uint16, size)
-		for i := uint16(0); i < size; i++ {
-			binary.Read(buf, binary.LittleEndian, &mulBlankCol.Xfs[i])
+		if len(data) < 8 {
+			break
 		}
-		binary.Read(buf, binary.LittleEndian, &mulBlankCol.LastColB)
+
+		size := (len(data) - 6) / 2
+		mulBlankCol := &MulBlankCol{Col: decodeCol(data), Xfs: make([]uint16, size)}
+
+		for i := range mulBlankCol.Xfs {
+			mulBlankCol.Xfs[i] = binary.LittleEndian.Uint16(data[4+2*i:])
+		}
+
+		mulBlankCol.LastColB = mulBlankCol.FirstColB + uint16(size) - 1
 		col = mulBlankCol
 	case 0x203: // NUMBER
-		col = new(NumberCol)
-		binary.Read(buf, binary.LittleEndian, col)
+		if len(data) < 14 {
+			break
+		}
+
+		col = &NumberCol{
+			Col:   decodeCol(data),
+			Index: binary.LittleEndian.Uint16(data[4:]),
+			Float: math.Float64frombits(binary.LittleEndian.Uint64(data[6:])),
+		}
 	case 0x06: // FORMULA
+		if len(data) < 20 {
+			break
+		}
+
 		formulaCol := new(FormulaCol)
-		binary.Read(buf, binary.LittleEndian, &formulaCol.Header)
-		formulaCol.Bts = make([]byte, b.Size-20)
-		binary.Read(buf, binary.LittleEndian, &formulaCol.Bts)
+		formulaCol.Header.Col = decodeCol(data)
+		formulaCol.Header.IndexXf = binary.LittleEndian.Uint16(data[4:])
+		copy(formulaCol.Header.Result[:], data[6:14])
+		formulaCol.Header.Flags = binary.LittleEndian.Uint16(data[14:])
+		formulaCol.Bts = append([]byte(nil), data[20:]...)
 		col = formulaCol
 	case 0x207: // STRING = FORMULA-VALUE is expected right after FORMULA
-		if ch, ok := colPre.(*FormulaCol); ok {
+		if ch, ok := colPre.(*FormulaCol); ok && len(data) >= 2 {
 			formulaStringCol := new(FormulaStringCol)
 			formulaStringCol.Col = ch.Header.Col
-			var cStringLen uint16
-			binary.Read(buf, binary.LittleEndian, &cStringLen)
-			str, err := w.wb.getString(buf, cStringLen)
+			w.strReader.Reset(data[2:])
+			str, err := w.wb.getString(&w.strReader, binary.LittleEndian.Uint16(data))
 
 			if nil == err {
 				formulaStringCol.RenderedValue = str
@@ -135,22 +278,39 @@ func (w *WorkSheet) parseBof(buf io.ReadSeeker, b *bof, pre *bof, colPre interfa
 			col = formulaStringCol
 		}
 	case 0x27e: // RK
-		col = new(RkCol)
-		binary.Read(buf, binary.LittleEndian, col)
+		if len(data) < 10 {
+			break
+		}
+
+		col = &RkCol{Col: decodeCol(data), Xfrk: decodeXfRk(data[4:])}
 	case 0xFD: // LABELSST
-		col = new(LabelsstCol)
-		binary.Read(buf, binary.LittleEndian, col)
-	case 0x204:
+		if len(data) < 10 {
+			break
+		}
+
+		col = &LabelsstCol{
+			Col: decodeCol(data),
+			Xf:  binary.LittleEndian.Uint16(data[4:]),
+			Sst: binary.LittleEndian.Uint32(data[6:]),
+		}
+	case 0x204: // LABEL
+		if len(data) < 8 {
+			break
+		}
+
 		c := new(labelCol)
-		binary.Read(buf, binary.LittleEndian, &c.BlankCol)
-		var count uint16
-		binary.Read(buf, binary.LittleEndian, &count)
-		c.Str, _ = w.wb.getString(buf, count)
+		c.BlankCol = BlankCol{Col: decodeCol(data), Xf: binary.LittleEndian.Uint16(data[4:])}
+		w.strReader.Reset(data[8:])
+		c.Str, _ = w.wb.getString(&w.strReader, binary.LittleEndian.Uint16(data[6:]))
 		col = c
 	case 0x201: // BLANK
-		col = new(BlankCol)
-		binary.Read(buf, binary.LittleEndian, col)
+		if len(data) < 6 {
+			break
+		}
+
+		col = &BlankCol{Col: decodeCol(data), Xf: binary.LittleEndian.Uint16(data[4:])}
 	case 0x1b8: // HYPERLINK
+		buf := bytes.NewReader(data)
 		var hyperlink HyperLink
 		binary.Read(buf, binary.LittleEndian, &hyperlink.CellRange)
 		buf.Seek(20, 1)
@@ -180,7 +340,7 @@ func (w *WorkSheet) parseBof(buf io.ReadSeeker, b *bof, pre *bof, colPre interfa
 				var upCount uint16
 				binary.Read(buf, binary.LittleEndian, &upCount)
 				binary.Read(buf, binary.LittleEndian, &count)
-				bts := make([]byte, count)
+				bts := make([]byte, min(int64(count), buf.Size()))
 				binary.Read(buf, binary.LittleEndian, &bts)
 				hyperlink.ShortedFilePath = string(bts)
 				buf.Seek(24, 1)
@@ -196,26 +356,45 @@ func (w *WorkSheet) parseBof(buf io.ReadSeeker, b *bof, pre *bof, colPre interfa
 
 		if flag&0x8 != 0 {
 			binary.Read(buf, binary.LittleEndian, &count)
-			bts := make([]uint16, count)
-			binary.Read(buf, binary.LittleEndian, &bts)
-			runes := utf16.Decode(bts[:len(bts)-1])
-			hyperlink.TextMark = string(runes)
+			hyperlink.TextMark = b.utf16String(buf, count)
 		}
 
 		w.addRange(&hyperlink.CellRange, &hyperlink)
 	case 0x809:
-		buf.Seek(int64(b.Size), 1)
 	case 0xa:
 	default:
 		// log.Printf("Unknow %X,%d\n", b.Id, b.Size)
-		buf.Seek(int64(b.Size), 1)
 	}
 
 	if col != nil {
 		w.add(col)
 	}
 
-	return b, col
+	return col
+}
+
+// decodeCol reads the row and column that start every cell record.
+func decodeCol(data []byte) Col {
+	return Col{RowB: binary.LittleEndian.Uint16(data), FirstColB: binary.LittleEndian.Uint16(data[2:])}
+}
+
+// decodeXfRk reads an XF index followed by an RK value.
+func decodeXfRk(data []byte) XfRk {
+	return XfRk{Index: binary.LittleEndian.Uint16(data), Rk: RK(binary.LittleEndian.Uint32(data[2:]))}
+}
+
+// decodeRowInfo reads the fixed part of a ROW record.
+func decodeRowInfo(data []byte) *rowInfo {
+	return &rowInfo{
+		Index:    binary.LittleEndian.Uint16(data),
+		Fcell:    binary.LittleEndian.Uint16(data[2:]),
+		Lcell:    binary.LittleEndian.Uint16(data[4:]),
+		Height:   binary.LittleEndian.Uint16(data[6:]),
+		Notused:  binary.LittleEndian.Uint16(data[8:]),
+		Notused2: binary.LittleEndian.Uint16(data[10:]),
+		Flags:    binary.LittleEndian.Uint32(data[12:]),
+		record:   true,
+	}
 }
 
 func (w *WorkSheet) add(content interface{}) {
@@ -226,13 +405,37 @@ func (w *WorkSheet) add(content interface{}) {
 	}
 }
 
+// maxColIndex is the largest column number a BIFF8 sheet can hold. Records past it are
+// dropped, as rows store their cells densely from the first column to the last.
+const maxColIndex = 0xFF
+
 func (w *WorkSheet) addCell(col Coler, contentHandler contentHandler) {
+	if contentHandler.LastCol() > maxColIndex {
+		return
+	}
+
+	w.cells += int(contentHandler.LastCol()-contentHandler.FirstCol()) + 1
+	if w.wb.checkLimit("MaxCells", int64(w.cells), int64(w.wb.opts.Limits.MaxCells)) != nil {
+		return
+	}
+
 	w.addContent(col.Row(), contentHandler)
 }
 
 func (w *WorkSheet) addRange(rang Ranger, contentHandler contentHandler) {
-	for i := rang.FirstRow(); i <= rang.LastRow(); i++ {
-		w.addContent(i, contentHandler)
+	if contentHandler.LastCol() > maxColIndex {
+		return
+	}
+
+	span := max(int(contentHandler.LastCol())-int(contentHandler.FirstCol())+1, 1)
+
+	for i := int(rang.FirstRow()); i <= int(rang.LastRow()); i++ {
+		w.cells += span
+		if w.wb.checkLimit("MaxCells", int64(w.cells), int64(w.wb.opts.Limits.MaxCells)) != nil {
+			return
+		}
+
+		w.addContent(uint16(i), contentHandler)
 	}
 }
 
@@ -250,7 +453,7 @@ func (w *WorkSheet) addContent(rowNum uint16, contentHandler contentHandler) {
 		row.info.Lcell = contentHandler.LastCol()
 	}
 
-	row.cols[contentHandler.FirstCol()] = contentHandler
+	row.setCell(contentHandler)
 }
 
 func (w *WorkSheet) addRow(info *rowInfo) (row *Row) {
@@ -262,9 +465,41 @@ func (w *WorkSheet) addRow(info *rowInfo) (row *Row) {
 	if row, ok = w.rows[info.Index]; ok {
 		row.info = info
 	} else {
-		row = &Row{info: info, cols: make(map[uint16]contentHandler)}
+		if w.wb.checkLimit("MaxRowsPerSheet", int64(len(w.rows)+1), int64(w.wb.opts.Limits.MaxRowsPerSheet)) != nil {
+			return &Row{sheet: w, info: info}
+		}
+
+		row = &Row{sheet: w, info: info}
 		w.rows[info.Index] = row
 	}
 
 	return
 }
+
+// twipsPerPoint converts the row heights stored in the file, in twips, to points.
+const twipsPerPoint = 20
+
+// defaultRowHeight is the row height Excel uses when a sheet has no DEFAULTROWHEIGHT record, in twips.
+const defaultRowHeight = 255
+
+// DefaultRowHeight returns the height, in points, of rows without a ROW record.
+func (w *WorkSheet) DefaultRowHeight() float64 {
+	if w == nil || w.defaultRowHeight == 0 {
+		return float64(defaultRowHeight) / twipsPerPoint
+	}
+
+	return float64(w.defaultRowHeight) / twipsPerPoint
+}
+
+// setDefaultRowHeight decodes a DEFAULTROWHEIGHT record.
+func (w *WorkSheet) setDefaultRowHeight(data []byte) {
+	if len(data) < 4 {
+		return
+	}
+
+	flags := binary.LittleEndian.Uint16(data)
+	w.defaultRowHidden = flags&0x2 != 0
+
+	// The height of hidden rows is the one they get once unhidden
+	w.defaultRowHeight = binary.LittleEndian.Uint16(data[2:])
+}
@@ -2,6 +2,7 @@ package xls
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
 	"os"
@@ -17,9 +18,15 @@ var ErrWorkbookNotFound = errors.New("xls: no Workbook or Book stream found")
 // It returns a parsed WorkBook object, or an error if the file could not be opened
 // or parsed successfully.
 func Open(file string) (*WorkBook, error) {
+	return OpenContext(context.Background(), file, nil)
+}
+
+// OpenContext is like Open, but parsing stops with ctx.Err() as soon as ctx is done,
+// and opts (which may be nil) tune the parser, e.g. to report progress.
+func OpenContext(ctx context.Context, file string, opts *Options) (*WorkBook, error) {
 	fi, err := os.Open(file)
 	if err == nil {
-		return OpenReader(fi)
+		return OpenReaderContext(ctx, fi, opts)
 	}
 
 	return nil, err
@@ -39,7 +46,8 @@ func OpenWithCloser(file string) (*WorkBook, io.Closer, error) {
 }
 
 // OpenStream loads an XLS workbook from any io.Reader (e.g., network stream, compressed archive).
-// Since the XLS format requires seeking, the entire input is buffered into memory.
+// Since the XLS format requires seeking, the entire input is buffered into memory, so r may be
+// closed as soon as OpenStream returns. Use OpenReaderAt to read a file in place instead.
 // Not recommended for very large XLS files due to memory usage.
 func OpenStream(r io.Reader) (*WorkBook, error) {
 	buf := new(bytes.Buffer)
@@ -50,9 +58,23 @@ func OpenStream(r io.Reader) (*WorkBook, error) {
 	return OpenReader(bytes.NewReader(buf.Bytes()))
 }
 
+// OpenReaderAt parses an XLS workbook of the given size from r using positional reads only.
+// Nothing is buffered up front and no cursor is shared with other users of r, so an *os.File,
+// a memory-mapped file or an object-storage client can serve several workbooks at once.
+func OpenReaderAt(r io.ReaderAt, size int64) (*WorkBook, error) {
+	return OpenReader(io.NewSectionReader(r, 0, size))
+}
+
 // OpenReader parses an XLS workbook from a seekable input stream (e.g., file, bytes.Reader).
 // The reader must implement io.ReadSeeker as the underlying OLE2 format requires random access.
 func OpenReader(reader io.ReadSeeker) (*WorkBook, error) {
+	return OpenReaderContext(context.Background(), reader, nil)
+}
+
+// OpenReaderContext is like OpenReader, but parsing stops with ctx.Err() as soon as ctx is done,
+// and opts (which may be nil) tune the parser. The context only governs opening; use
+// WorkBook.GetSheetContext to bound the parsing of individual sheets.
+func OpenReaderContext(ctx context.Context, reader io.ReadSeeker, opts *Options) (*WorkBook, error) {
 	// Open the OLE2 compound document structure
 	ole, err := ole2.Open(reader)
 	if err != nil {
@@ -90,5 +112,13 @@ func OpenReader(reader io.ReadSeeker) (*WorkBook, error) {
 	}
 
 	// Construct the WorkBook from the selected stream
-	return newWorkBookFromOle2(ole.OpenFile(book, root)), nil
+	wb, err := newWorkBookFromOle2(ctx, ole.OpenFile(book, root), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the container for the storages of embedded objects
+	wb.ole, wb.oleDir, wb.oleRoot = ole, dir, root
+
+	return wb, nil
 }
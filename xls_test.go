@@ -1,6 +1,10 @@
 package xls
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
 	"testing"
 )
 
@@ -37,3 +41,139 @@ func TestOpen(t *testing.T) {
 		}
 	}
 }
+
+func TestOpenReaderAt(t *testing.T) {
+	t.Parallel()
+
+	file, err := os.Open("testdata/bigtable.xls")
+	if err != nil {
+		t.Fatalf("failed to open XLS file: %v", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		t.Fatalf("failed to stat XLS file: %v", err)
+	}
+
+	// Two workbooks share one *os.File without interfering with each other
+	first, err := OpenReaderAt(file, info.Size())
+	if err != nil {
+		t.Fatalf("OpenReaderAt failed: %v", err)
+	}
+
+	second, err := OpenReaderAt(file, info.Size())
+	if err != nil {
+		t.Fatalf("OpenReaderAt failed: %v", err)
+	}
+
+	want := first.GetSheet(0).Row(100).Col(2)
+	if got := second.GetSheet(0).Row(100).Col(2); got != want {
+		t.Errorf("row 100 col 2: got %q, want %q", got, want)
+	}
+}
+
+// TestOpenStream checks that OpenStream buffers its input: the file can be closed right away,
+// and sheets are still read later.
+func TestOpenStream(t *testing.T) {
+	t.Parallel()
+
+	file, err := os.Open("testdata/bigtable.xls")
+	if err != nil {
+		t.Fatalf("failed to open XLS file: %v", err)
+	}
+
+	workBook, err := OpenStream(file)
+	file.Close()
+
+	if err != nil {
+		t.Fatalf("OpenStream failed: %v", err)
+	}
+
+	want, err := Open("testdata/bigtable.xls")
+	if err != nil {
+		t.Fatalf("failed to open XLS file: %v", err)
+	}
+
+	if got, exp := workBook.GetSheet(0).Row(100).Col(2), want.GetSheet(0).Row(100).Col(2); got != exp {
+		t.Errorf("row 100 col 2 after closing the file: got %q, want %q", got, exp)
+	}
+}
+
+func TestOpenContext(t *testing.T) {
+	t.Parallel()
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := OpenContext(canceled, "testdata/bigtable.xls", nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("OpenContext with canceled context: got %v, want %v", err, context.Canceled)
+	}
+
+	var reports []Progress
+
+	workBook, err := OpenContext(context.Background(), "testdata/bigtable.xls", &Options{
+		Progress: func(p Progress) { reports = append(reports, p) },
+	})
+	if err != nil {
+		t.Fatalf("OpenContext failed: %v", err)
+	}
+
+	if len(reports) == 0 || !reports[len(reports)-1].Done {
+		t.Fatalf("expected a final progress report, got %v", reports)
+	}
+
+	if _, err := workBook.GetSheetContext(canceled, 0); !errors.Is(err, context.Canceled) {
+		t.Fatalf("GetSheetContext with canceled context: got %v, want %v", err, context.Canceled)
+	}
+
+	reports = nil
+
+	sheet, err := workBook.GetSheetContext(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("GetSheetContext failed: %v", err)
+	}
+
+	last := reports[len(reports)-1]
+	if !last.Done || last.Sheet != sheet.Name || last.Records < int(sheet.MaxRow) {
+		t.Errorf("unexpected final sheet progress report: %+v", last)
+	}
+}
+
+// TestParseContext reads a workbook stream to its end, through the substream of a sheet.
+func TestParseContext(t *testing.T) {
+	t.Parallel()
+
+	stream := bytes.Join([][]byte{
+		biffRecord(0x809, []byte{0, 6, 5, 0}, make([]byte, 12)),
+		biffRecord(0x42, []byte{0xE4, 0x04}),
+		biffRecord(0x0A),
+		biffRecord(0x809, []byte{0, 6, 0x10, 0}, make([]byte, 12)),
+		biffRecord(0x0A),
+	}, nil)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := new(WorkBook).ParseContext(canceled, bytes.NewReader(stream)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ParseContext with canceled context: got %v, want %v", err, context.Canceled)
+	}
+
+	var last Progress
+
+	workBook := &WorkBook{opts: Options{Progress: func(p Progress) { last = p }}}
+	if err := workBook.ParseContext(context.Background(), bytes.NewReader(stream)); err != nil {
+		t.Fatalf("ParseContext failed: %v", err)
+	}
+
+	if !last.Done || last.Records != 5 || workBook.Codepage != 1252 {
+		t.Errorf("ParseContext: code page %d, final progress report %+v, want 1252 after 5 records", workBook.Codepage, last)
+	}
+
+	workBook = new(WorkBook)
+	workBook.Parse(bytes.NewReader(stream))
+
+	if workBook.Codepage != 1252 || workBook.Type != 0x5 {
+		t.Errorf("Parse: code page %d, type %#x, want 1252 and the globals type 0x5", workBook.Codepage, workBook.Type)
+	}
+}